@@ -1,25 +1,93 @@
 package main
 
 import (
-	"encoding/json"
+	"bytes"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	validator "validator/validator"
 )
 
+// options holds the command line arguments used to analyze each file
+type options struct {
+	mode            string
+	functionName    string
+	variableName    string
+	declarationPath string
+	newName         string
+	entry           string
+	runArguments    string
+	tracePath       string
+	maxSteps        int
+	maxDepth        int
+	unusedVariables validator.UnusedVariablesOptions
+	annotatePaths   bool
+	check           bool
+	fix             bool
+	dryRun          bool
+	inputFormat     *validator.InputFormat // nil to select the format by the file extension
+	numericPolicy   validator.NumericPolicy
+	modules         validator.Modules // modules declared by the given files
+}
+
+// loadedFile is a file decoded before the analysis, so that its calls can refer to the modules of the other files
+type loadedFile struct {
+	path        string
+	inputFormat validator.InputFormat
+	data        []byte
+	program     validator.Program
+	sourceMap   validator.SourceMap
+	err         error // error reading or decoding the file
+}
+
+// fileList is the value of the -file flag, which may be repeated
+type fileList []string
+
+func (f *fileList) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *fileList) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
 // -----------------------------------------
 // main function
 // -----------------------------------------
 func main() {
 	// read arguments from command line
-	filePath := flag.String("file", "", "Path to the JSON file")
+	var files fileList
+	flag.Var(&files, "file", "Path to a file holding a program, a directory, a glob pattern, or - for the standard input. May be repeated, and more files may follow the flags")
 	mode := flag.String("mode", "", "Mode of operation")
+	functionName := flag.String("function", "", "Function whose use-def and def-use chains are listed in chains mode, renamed in rename mode or inlined in inline mode")
+	variableName := flag.String("variable", "", "Variable of -function renamed in rename mode")
+	declarationPath := flag.String("declaration", "", "JSON path of the declaration of -variable, when several variables share its name")
+	newName := flag.String("to", "", "New name of the function or variable in rename mode")
+	entry := flag.String("entry", "main", "Function to call in run mode, or whose reachable functions are kept in dce mode")
+	runArguments := flag.String("args", "", "Comma separated arguments passed to the entry function in run mode")
+	tracePath := flag.String("trace", "", "Path of the JSON lines execution trace written in run mode, - for stderr")
+	maxSteps := flag.Int("max-steps", 1000000, "Maximum number of statements executed in run mode, 0 for no limit")
+	maxDepth := flag.Int("max-depth", validator.DefaultMaxCallDepth, "Maximum depth of nested function calls in run mode, 0 for no limit")
+	ignoreUnusedParameters := flag.Bool("ignore-unused-parameters", false, "Don't report unused parameters in unused_variables mode")
+	ignoreUnusedPattern := flag.String("ignore-unused-pattern", "", "Regular expression of the variable names not reported in unused_variables mode, e.g. ^_")
+	annotatePaths := flag.Bool("annotate-paths", false, "End each line with the JSON path of the function or statement in print mode")
+	check := flag.Bool("check", false, "Only report whether the file is formatted in fmt mode, printing the changes as a diff")
+	fix := flag.Bool("fix", false, "Remove the unused variables and dead stores from the file in unused_variables mode")
+	dryRun := flag.Bool("dry-run", false, "Print the changes made by -fix as a diff instead of writing the file")
+	inputFormatName := flag.String("input-format", "", "Format of the file: json, vl, yaml or toml, selected by the file extension by default")
+	numericPolicyName := flag.String("numeric-policy", validator.PermissiveNumericPolicy.Name, "Accepted numerical values: permissive, finite or integer")
 	flag.Parse()
+	files = append(files, flag.Args()...)
 
 	// Validate command line arguments
-	if *filePath == "" {
+	if len(files) == 0 {
 		fmt.Println("File path is required.")
 		os.Exit(1)
 	}
@@ -27,38 +95,471 @@ func main() {
 		fmt.Println("Mode is required.")
 		os.Exit(1)
 	}
+	if *dryRun && !*fix {
+		fmt.Println("-dry-run requires -fix.")
+		os.Exit(1)
+	}
+	opts := options{mode: *mode, functionName: *functionName, variableName: *variableName, declarationPath: *declarationPath,
+		newName: *newName, entry: *entry, runArguments: *runArguments, tracePath: *tracePath, maxSteps: *maxSteps,
+		maxDepth: *maxDepth, annotatePaths: *annotatePaths, check: *check, fix: *fix, dryRun: *dryRun}
+	var err error
+	opts.numericPolicy, err = validator.NumericPolicyByName(*numericPolicyName)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if *inputFormatName != "" {
+		inputFormat, err := validator.InputFormatByName(*inputFormatName)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		opts.inputFormat = &inputFormat
+	}
+	opts.unusedVariables = validator.UnusedVariablesOptions{IgnoreParameters: *ignoreUnusedParameters}
+	if *ignoreUnusedPattern != "" {
+		opts.unusedVariables.IgnoreNames, err = regexp.Compile(*ignoreUnusedPattern)
+		if err != nil {
+			fmt.Println("Invalid -ignore-unused-pattern:", err)
+			os.Exit(1)
+		}
+	}
 
-	// Read the JSON file
-	jsonData, err := ioutil.ReadFile(*filePath)
+	filePaths, grouped, err := expandFiles(files)
 	if err != nil {
-		fmt.Println("Error reading JSON file:", err)
+		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	// Parse the JSON into the AST structure
-	var program validator.Program
-	err = json.Unmarshal(jsonData, &program)
+	// All the files are decoded first, so that the modules they declare can be imported by any of them
+	loaded := make([]loadedFile, len(filePaths))
+	programs := []validator.Program{}
+	for i, filePath := range filePaths {
+		loaded[i] = loadFile(filePath, opts.inputFormat)
+		if loaded[i].err == nil {
+			programs = append(programs, loaded[i].program)
+		}
+	}
+	opts.modules, err = validator.LoadModules(programs)
 	if err != nil {
-		fmt.Println("Error parsing JSON:", err)
-		return
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	// Each file is analyzed on its own. The results are grouped by file when several files are given, followed
+	// by a summary.
+	results := summary{files: len(filePaths)}
+	for i, file := range loaded {
+		if grouped {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("== %v ==\n", file.path)
+		}
+		isValid, err := analyzeFile(file, opts)
+		if err != nil {
+			fmt.Println(err)
+		}
+		results.add(isValid, err)
 	}
+	if grouped {
+		fmt.Printf("\n%v\n", results)
+	}
+	if results.failed(opts.mode) {
+		os.Exit(1)
+	}
+}
+
+// summary counts the outcomes of the analyzed files, each file being counted once
+type summary struct {
+	files, valid, invalid, skipped, errors int
+}
+
+// add counts the outcome of a file: skipped, failed with an error, valid or invalid
+func (s *summary) add(isValid bool, err error) {
+	switch {
+	case errors.Is(err, errSkipped):
+		s.skipped++
+	case err != nil:
+		s.errors++
+	case isValid:
+		s.valid++
+	default:
+		s.invalid++
+	}
+}
 
-	// Print the parsed AST
-	// fmt.Printf("%v\n", program)
+// String returns the summary line, e.g. 3 files: 1 valid, 1 invalid, 1 failed
+func (s summary) String() string {
+	text := fmt.Sprintf("%v files: %v valid, %v invalid", s.files, s.valid, s.invalid)
+	if s.skipped > 0 {
+		text += fmt.Sprintf(", %v skipped", s.skipped)
+	}
+	if s.errors > 0 {
+		text += fmt.Sprintf(", %v failed", s.errors)
+	}
+	return text
+}
+
+// failed returns true if the exit status is 1. It only depends on the outcome of the mode, whether the files are
+// grouped or not: the other modes may analyze invalid programs.
+func (s summary) failed(mode string) bool {
+	return s.errors > 0 || (mode == "verify" && s.invalid > 0)
+}
 
-	switch *mode {
+// errSkipped is wrapped by the errors of the files which the mode doesn't apply to, e.g. the YAML files in fmt
+// mode. They are reported as skipped rather than failed.
+var errSkipped = errors.New("Skipped")
+
+// expandFiles returns the files named by the arguments: files, directories searched recursively for the files of
+// the input formats, glob patterns, or - for the standard input. The results are grouped by file if several files,
+// a directory or a glob pattern are given.
+func expandFiles(arguments []string) (files []string, grouped bool, err error) {
+	grouped = len(arguments) > 1
+	for _, argument := range arguments {
+		matches := []string{argument}
+		if argument != "-" && strings.ContainsAny(argument, "*?[") {
+			matches, err = filepath.Glob(argument)
+			if err != nil {
+				return nil, false, fmt.Errorf("Invalid glob pattern: %v: %v", argument, err)
+			}
+			if len(matches) == 0 {
+				return nil, false, fmt.Errorf("No file matches: %v", argument)
+			}
+			grouped = true
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if match == "-" || err != nil || !info.IsDir() {
+				// a missing file is reported when it is read
+				files = append(files, match)
+				continue
+			}
+			grouped = true
+			err = filepath.Walk(match, func(path string, info os.FileInfo, err error) error {
+				if err == nil && !info.IsDir() && hasInputFormatExtension(path) {
+					files = append(files, path)
+				}
+				return err
+			})
+			if err != nil {
+				return nil, false, fmt.Errorf("Error reading directory: %v", err)
+			}
+		}
+	}
+	return files, grouped, nil
+}
+
+// hasInputFormatExtension returns true if the extension of the file selects one of the input formats
+func hasInputFormatExtension(filePath string) bool {
+	extension := strings.ToLower(filepath.Ext(filePath))
+	for _, format := range validator.InputFormats {
+		for _, formatExtension := range format.Extensions {
+			if extension == formatExtension {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readFile returns the content of a file, or of the standard input for -
+func readFile(filePath string) ([]byte, error) {
+	if filePath == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	return ioutil.ReadFile(filePath)
+}
+
+// writeFile writes the content of a file, or prints it for the standard input
+func writeFile(filePath string, data []byte) error {
+	if filePath == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return ioutil.WriteFile(filePath, data, 0644)
+}
+
+// loadFile reads and decodes a file, in the given format or in the format selected by its extension if nil
+func loadFile(filePath string, inputFormat *validator.InputFormat) loadedFile {
+	file := loadedFile{path: filePath, inputFormat: validator.InputFormatForFile(filePath)}
+	if inputFormat != nil {
+		file.inputFormat = *inputFormat
+	}
+
+	// Read the file
+	file.data, file.err = readFile(filePath)
+	if file.err != nil {
+		file.err = fmt.Errorf("Error reading file: %v", file.err)
+		return file
+	}
+
+	// Decode the file into the AST structure
+	file.program, file.sourceMap, file.err = file.inputFormat.Decode(file.data)
+	if file.err != nil {
+		file.err = fmt.Errorf("Error parsing %v: %v", filePath, file.err)
+	}
+	return file
+}
+
+// analyzeFile runs the mode on the program of a file, returning whether the program is valid
+func analyzeFile(file loadedFile, opts options) (bool, error) {
+	if file.err != nil {
+		return false, file.err
+	}
+	filePath, inputFormat, data, program, sourceMap := file.path, file.inputFormat, file.data, file.program, file.sourceMap
+	var err error
+	// located returns the text of a diagnostic, whose JSON paths are followed by their line if the file isn't JSON
+	located := func(value interface{}) string {
+		return sourceMap.Annotate(fmt.Sprint(value))
+	}
+
+	// Verify the program, printing the reasons why it is invalid in verify mode
+	isValid := validator.ValidateModule(program, opts.modules, opts.numericPolicy, opts.mode == "verify")
+
+	switch opts.mode {
 	case "verify":
-		// Verify the program
-		isValid := validator.ValidateProgramRec(program, true)
 		fmt.Println("Is program valid?", isValid)
 	case "unused_variables":
-		unusedVariables := validator.UnusedVariables(program)
-		fmt.Println("unusedVariables: ", unusedVariables)
+		// the program fixed from the standard input is printed on stdout, so the report goes to stderr
+		report := io.Writer(os.Stdout)
+		if opts.fix && !opts.dryRun && filePath == "-" {
+			report = os.Stderr
+		}
+		unusedVariables := validator.UnusedVariablesWithOptions(program, opts.unusedVariables)
+		fmt.Fprintln(report, "unusedVariables: ")
+		for _, unused := range unusedVariables {
+			fmt.Fprintln(report, located(unused))
+		}
+		if opts.fix {
+			return isValid, fixUnusedVariables(program, opts.modules, inputFormat, filePath, data, opts.dryRun, report)
+		}
 	case "functions_dependancies":
 		functions_dependancies := validator.FindFunctionCalls(program)
 		fmt.Println("functions_dependancies: ", functions_dependancies)
+	case "types":
+		typeCheckResult := validator.CheckTypes(program)
+		fmt.Println("types: ", typeCheckResult.Variables)
+		for _, typeError := range typeCheckResult.Errors {
+			fmt.Println("Type error:", located(typeError))
+		}
+		fmt.Println("Is program well typed?", len(typeCheckResult.Errors) == 0)
+	case "constants":
+		constants := validator.PropagateConstants(program)
+		fmt.Println("constants before each statement: ")
+		for _, statement := range constants.Statements {
+			fmt.Printf("%v %v: %v\n", statement.Function, located(statement.Path), validator.FormatConstants(statement.Constants))
+		}
+	case "dead_stores":
+		deadStores := validator.FindDeadStores(program)
+		fmt.Println("dead stores: ")
+		for _, deadStore := range deadStores {
+			fmt.Println(located(deadStore))
+		}
+	case "hazards":
+		if !validator.ValidateModule(program, opts.modules, opts.numericPolicy, true) {
+			return isValid, errors.New("Can't check the arithmetic hazards of an invalid program.")
+		}
+		hazards := validator.CheckArithmeticHazards(program, opts.numericPolicy)
+		fmt.Println("arithmetic hazards: ")
+		for _, hazard := range hazards {
+			fmt.Println(located(hazard))
+		}
+		if len(hazards) > 0 {
+			return isValid, errors.New("The program has arithmetic hazards.")
+		}
+	case "chains":
+		chains, err := program.Chains(opts.functionName)
+		if err != nil {
+			return isValid, err
+		}
+		fmt.Println("use-def chains: ")
+		for _, chain := range chains.UseDef {
+			definitions := []string{}
+			for _, definition := range chain.Definitions {
+				definitions = append(definitions, definition.Path)
+			}
+			fmt.Println(located(fmt.Sprintf("%v at %v (declared at %v) <- %v", chain.Use.Declaration.Variable, chain.Use.Path, chain.Use.Declaration.Path, definitions)))
+		}
+		fmt.Println("def-use chains: ")
+		for _, chain := range chains.DefUse {
+			uses := []string{}
+			for _, use := range chain.Uses {
+				uses = append(uses, use.Path)
+			}
+			fmt.Println(located(fmt.Sprintf("%v at %v (declared at %v) -> %v", chain.Definition.Declaration.Variable, chain.Definition.Path, chain.Definition.Declaration.Path, uses)))
+		}
+	case "run":
+		// Execute the program, starting at the entry function
+		arguments := []validator.Value{}
+		if opts.runArguments != "" {
+			for _, arg := range strings.Split(opts.runArguments, ",") {
+				arguments = append(arguments, validator.ParseValue(strings.TrimSpace(arg)))
+			}
+		}
+		interpreter := validator.NewInterpreter(program, validator.DefaultBuiltins(os.Stdout))
+		interpreter.MaxSteps = opts.maxSteps
+		interpreter.MaxCallDepth = opts.maxDepth
+		var tracer *validator.JSONLinesTracer
+		if opts.tracePath != "" {
+			// the trace doesn't go to stdout, which holds the output of the program and its result
+			traceFile := os.Stderr
+			if opts.tracePath != "-" {
+				traceFile, err = os.Create(opts.tracePath)
+				if err != nil {
+					return isValid, fmt.Errorf("Error creating trace file: %v", err)
+				}
+				defer traceFile.Close()
+			}
+			tracer = validator.NewJSONLinesTracer(traceFile)
+			interpreter.Trace = tracer.Trace
+		}
+		result, err := interpreter.Run(opts.entry, arguments)
+		if tracer != nil && tracer.Err != nil {
+			if err != nil {
+				return isValid, fmt.Errorf("Runtime error: %v, and error writing trace: %v", located(err), tracer.Err)
+			}
+			return isValid, fmt.Errorf("Error writing trace: %v", tracer.Err)
+		}
+		if err != nil {
+			return isValid, fmt.Errorf("Runtime error: %v", located(err))
+		}
+		fmt.Println("result:", result)
+	case "fmt":
+		if inputFormat.Encode == nil {
+			return isValid, fmt.Errorf("%w: can't format %v files.", errSkipped, inputFormat.Name)
+		}
+		if err := rewritable(inputFormat, data); err != nil {
+			return isValid, fmt.Errorf("Can't format the file: %v", err)
+		}
+		formatted := inputFormat.Encode(program)
+		if filePath == "-" && !opts.check {
+			// the standard input can't be rewritten, the formatted program is printed instead
+			return isValid, writeFile(filePath, formatted)
+		}
+		if bytes.Equal(formatted, data) {
+			fmt.Println("The file is formatted.")
+			return isValid, nil
+		}
+		if opts.check {
+			fmt.Print(validator.UnifiedDiff(filePath, filePath, string(data), string(formatted)))
+			return isValid, errors.New("The file is not formatted.")
+		}
+		err = writeFile(filePath, formatted)
+		if err != nil {
+			return isValid, fmt.Errorf("Error writing file: %v", err)
+		}
+		fmt.Println("The file is formatted.")
+	case "api":
+		if program.Module == "" {
+			fmt.Println("The program is not a module.")
+			return isValid, nil
+		}
+		fmt.Printf("api of module %v:\n", program.Module)
+		for _, function := range program.API() {
+			fmt.Println(function)
+		}
+	case "print":
+		fmt.Print(validator.PrintProgram(program, validator.PrintOptions{AnnotatePaths: opts.annotatePaths}))
+	case "rename":
+		renamed, err := rename(program, opts.modules, opts.functionName, opts.variableName, opts.declarationPath, opts.newName)
+		if err != nil {
+			return isValid, fmt.Errorf("Can't rename: %v", located(err))
+		}
+		fmt.Print(string(validator.FormatProgram(renamed)))
+	case "inline":
+		if !validator.ValidateModule(program, opts.modules, validator.PermissiveNumericPolicy, true) {
+			return isValid, errors.New("Can't inline an invalid program.")
+		}
+		inlined, calls, err := validator.InlineFunction(program, opts.functionName)
+		if err != nil {
+			return isValid, fmt.Errorf("Can't inline: %v", err)
+		}
+		for _, call := range calls {
+			fmt.Fprintf(os.Stderr, "inlined call to %v in %v at %v\n", opts.functionName, call.Function, located(call.Path))
+		}
+		fmt.Print(string(validator.FormatProgram(inlined)))
+	case "dce":
+		if !validator.ValidateModule(program, opts.modules, validator.PermissiveNumericPolicy, true) {
+			return isValid, errors.New("Can't eliminate the dead code of an invalid program.")
+		}
+		eliminated, removed, err := validator.EliminateDeadCode(program, opts.entry)
+		if err != nil {
+			return isValid, fmt.Errorf("Can't eliminate the dead code: %v", err)
+		}
+		for _, code := range removed {
+			fmt.Fprintln(os.Stderr, located(code))
+		}
+		fmt.Print(string(validator.FormatProgram(eliminated)))
 	default:
-		fmt.Println("Please enter a valid mode")
+		return isValid, errors.New("Please enter a valid mode")
 	}
+	return isValid, nil
+}
 
+// rename renames a function, or a variable of the function if variableName is given
+func rename(program validator.Program, modules validator.Modules, functionName string, variableName string, declarationPath string, newName string) (validator.Program, error) {
+	if !validator.ValidateModule(program, modules, validator.PermissiveNumericPolicy, true) {
+		return validator.Program{}, fmt.Errorf("the program is not valid")
+	}
+	if variableName == "" {
+		return validator.RenameFunction(program, functionName, newName)
+	}
+
+	declarations := []validator.Declaration{}
+	for _, declaration := range program.FindDeclarations(functionName, variableName) {
+		if declarationPath == "" || declaration.Path == declarationPath {
+			declarations = append(declarations, declaration)
+		}
+	}
+	switch len(declarations) {
+	case 0:
+		return validator.Program{}, fmt.Errorf("variable: %v is not declared in function: %v", variableName, functionName)
+	case 1:
+		return validator.RenameVariable(program, declarations[0], newName)
+	}
+	paths := []string{}
+	for _, declaration := range declarations {
+		paths = append(paths, declaration.Path)
+	}
+	return validator.Program{}, fmt.Errorf("variable: %v is declared at %v, select one using -declaration", variableName, strings.Join(paths, ", "))
+}
+
+// rewritable returns an error if rewriting the file would lose information, e.g. the comments of a .vl file
+func rewritable(format validator.InputFormat, data []byte) error {
+	if format.Rewritable == nil {
+		return nil
+	}
+	return format.Rewritable(data)
+}
+
+// fixUnusedVariables removes the unused variables and dead stores of a valid program, writing the fixed program
+// to the file, or printing the changes as a diff for a dry run. The removed statements are written to report.
+func fixUnusedVariables(program validator.Program, modules validator.Modules, format validator.InputFormat, filePath string, data []byte, dryRun bool, report io.Writer) error {
+	if format.Encode == nil {
+		return fmt.Errorf("%w: can't fix %v files.", errSkipped, format.Name)
+	}
+	if err := rewritable(format, data); err != nil && !dryRun {
+		return fmt.Errorf("Can't fix the file: %v", err)
+	}
+	if !validator.ValidateModule(program, modules, validator.PermissiveNumericPolicy, true) {
+		return errors.New("Can't fix an invalid program.")
+	}
+	fixed, removed := validator.FixUnusedVariables(program)
+	for _, statement := range removed {
+		fmt.Fprintln(report, statement)
+	}
+
+	// both programs are encoded, so that the diff only shows the fixes
+	original := format.Encode(program)
+	fixedData := format.Encode(fixed)
+	if dryRun {
+		fmt.Print(validator.UnifiedDiff(filePath, filePath, string(original), string(fixedData)))
+		return nil
+	}
+	err := writeFile(filePath, fixedData)
+	if err != nil {
+		return fmt.Errorf("Error writing file: %v", err)
+	}
+	return nil
 }
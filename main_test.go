@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSummary(t *testing.T) {
+	// each file is counted once: a skipped or failed file isn't counted as valid or invalid
+	filePaths := []string{
+		"data/valid/function_call.json",
+		"data/invalid/function_call_missing_required_parameter.json",
+		"data/formats/program.yaml",
+		"data/missing.json",
+	}
+	opts := options{mode: "fmt", check: true}
+	results := summary{files: len(filePaths)}
+	for _, filePath := range filePaths {
+		results.add(analyzeFile(loadFile(filePath, nil), opts))
+	}
+
+	expected := "4 files: 1 valid, 1 invalid, 1 skipped, 1 failed"
+	if results.String() != expected {
+		t.Errorf("Unexpected summary. Got %v, want %v", results, expected)
+	}
+	if !results.failed("fmt") {
+		t.Errorf("Expected a failure for the missing file")
+	}
+}
+
+func TestSummary_Failed(t *testing.T) {
+	testCases := []struct {
+		mode     string
+		isValid  bool
+		err      error
+		expected bool
+	}{
+		{"verify", true, nil, false},
+		{"verify", false, nil, true},
+		{"api", false, nil, false},
+		{"fmt", true, errSkipped, false},
+		{"fmt", true, errors.New("The file is not formatted."), true},
+	}
+	for _, testCase := range testCases {
+		results := summary{files: 1}
+		results.add(testCase.isValid, testCase.err)
+		if failed := results.failed(testCase.mode); failed != testCase.expected {
+			t.Errorf("%v %v %v: unexpected failure. Got %v, want %v", testCase.mode, testCase.isValid, testCase.err, failed, testCase.expected)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// -----------------------------------------
+// Constant propagation
+// -----------------------------------------
+/*
+	The analysis walks each function body in execution order, folding operations whose operands are constant using the
+	operation registry semantics, see EvaluateOperation. A variable holds a known constant after being assigned a
+	constant value, until it is assigned a value which isn't constant.
+		- parameters and function call results are never constant, since they depend on the caller or the called function.
+		- function calls can't change the variables of the caller, so they don't affect the known constants.
+		- operations which fail, e.g. a division by zero, are not constant.
+*/
+
+// StatementConstants holds the variables holding a known constant before a statement is executed
+type StatementConstants struct {
+	Function  string
+	Path      string // JSON path of the statement
+	Constants map[string]Value
+}
+
+// ConstantAnalysis is the result of the constant propagation of a program
+type ConstantAnalysis struct {
+	// Statements holds the known constants before each statement, in program order
+	Statements []StatementConstants
+	// Values maps the JSON path of each operand and statement with a constant value to that value
+	Values map[string]Value
+	// statementIndex maps the JSON path of each statement to its index in Statements
+	statementIndex map[string]int
+}
+
+// ConstantsBefore returns the variables holding a known constant before the statement at the given path is executed
+func (analysis ConstantAnalysis) ConstantsBefore(path string) map[string]Value {
+	index, ok := analysis.statementIndex[path]
+	if !ok {
+		return nil
+	}
+	return analysis.Statements[index].Constants
+}
+
+// ConstantValue returns the value of the operand or statement at the given path, if it is constant
+func (analysis ConstantAnalysis) ConstantValue(path string) (Value, bool) {
+	value, ok := analysis.Values[path]
+	return value, ok
+}
+
+// constantPropagator holds the state of the constant propagation of a function
+type constantPropagator struct {
+	analysis *ConstantAnalysis
+	function string
+	// scopes holds the variables declared in each surrounding block, a nil value means the variable isn't constant
+	scopes []map[string]*Value
+}
+
+// PropagateConstants computes which variables hold a known constant before each statement of the program.
+// It is assumed that the program is valid, i.e. ValidateProgramRec returns true.
+func PropagateConstants(program Program) ConstantAnalysis {
+	analysis := ConstantAnalysis{
+		Statements:     []StatementConstants{},
+		Values:         make(map[string]Value),
+		statementIndex: make(map[string]int),
+	}
+	for i, function := range program.Functions {
+		propagator := constantPropagator{analysis: &analysis, function: function.Name}
+		propagator.scopes = []map[string]*Value{make(map[string]*Value)}
+		for _, param := range function.Parameters {
+			propagator.scopes[0][param.Name] = nil
+		}
+		propagator.block(function.Body, functionPath(i)+".body")
+	}
+	return analysis
+}
+
+func (p *constantPropagator) block(block Block, path string) {
+	p.scopes = append(p.scopes, make(map[string]*Value))
+	for i, statement := range block.Statements {
+		p.statement(statement, elementPath(path, "statements", i))
+	}
+	p.scopes = p.scopes[:len(p.scopes)-1]
+}
+
+func (p *constantPropagator) statement(statement Statement, path string) {
+	p.analysis.statementIndex[path] = len(p.analysis.Statements)
+	p.analysis.Statements = append(p.analysis.Statements, StatementConstants{
+		Function:  p.function,
+		Path:      path,
+		Constants: p.knownConstants(),
+	})
+
+	switch statement.Type {
+	case "block":
+		p.block(statement.Block, path+".block")
+	case "variable_declaration":
+		p.scopes[len(p.scopes)-1][statement.Variable] = nil
+	case "operation", "function_call":
+		p.fold(statement, path)
+	}
+}
+
+// knownConstants returns the visible variables holding a known constant
+func (p *constantPropagator) knownConstants() map[string]Value {
+	constants := make(map[string]Value)
+	visible := make(set)
+	for i := len(p.scopes) - 1; i >= 0; i-- {
+		for name, value := range p.scopes[i] {
+			if _, shadowed := visible[name]; shadowed {
+				continue
+			}
+			visible.add(name)
+			if value != nil {
+				constants[name] = *value
+			}
+		}
+	}
+	return constants
+}
+
+// assign updates the innermost variable with the given name
+func (p *constantPropagator) assign(name string, value Value, constant bool) {
+	for i := len(p.scopes) - 1; i >= 0; i-- {
+		if _, declared := p.scopes[i][name]; declared {
+			if constant {
+				p.scopes[i][name] = &value
+			} else {
+				p.scopes[i][name] = nil
+			}
+			return
+		}
+	}
+}
+
+// lookup returns the value of the innermost variable with the given name, if it is constant
+func (p *constantPropagator) lookup(name string) (Value, bool) {
+	for i := len(p.scopes) - 1; i >= 0; i-- {
+		if value, declared := p.scopes[i][name]; declared {
+			if value == nil {
+				return Void, false
+			}
+			return *value, true
+		}
+	}
+	return Void, false
+}
+
+// fold returns the value of an operand if it is constant, recording it in the analysis.
+// All nested operands are folded, since nested assignments change the known constants.
+func (p *constantPropagator) fold(operand Statement, path string) (Value, bool) {
+	value, constant := p.foldOperand(operand, path)
+	if constant {
+		p.analysis.Values[path] = value
+	}
+	return value, constant
+}
+
+func (p *constantPropagator) foldOperand(operand Statement, path string) (Value, bool) {
+	switch operand.Type {
+	case "numerical", "string", "boolean":
+		value, err := LiteralValue(operand)
+		return value, err == nil
+	case "variable":
+		return p.lookup(operand.Variable)
+	case "function_call":
+		for i, arg := range operand.Arguments {
+			p.fold(arg, elementPath(path, "arguments", i))
+		}
+		return Void, false
+	case "operation":
+		if operand.OperationType == "assignment" && len(operand.Operands) == 2 {
+			value, constant := p.fold(operand.Operands[1], elementPath(path, "operands", 1))
+			p.assign(operand.Operands[0].Variable, value, constant)
+			return value, constant
+		}
+		values := make([]Value, len(operand.Operands))
+		constant := true
+		for i, nested := range operand.Operands {
+			value, nestedConstant := p.fold(nested, elementPath(path, "operands", i))
+			values[i] = value
+			constant = constant && nestedConstant
+		}
+		if !constant {
+			return Void, false
+		}
+		value, err := EvaluateOperation(operand.OperationType, values)
+		return value, err == nil
+	}
+	return Void, false
+}
+
+// FormatConstants returns the constants as a sorted list of assignments, e.g. "x = 10, y = 2.5"
+func FormatConstants(constants map[string]Value) string {
+	names := make([]string, 0, len(constants))
+	for name := range constants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	assignments := make([]string, len(names))
+	for i, name := range names {
+		assignments[i] = fmt.Sprintf("%v = %v", name, constants[name])
+	}
+	return strings.Join(assignments, ", ")
+}
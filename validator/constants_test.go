@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPropagateConstants_ConstantsBefore(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/constants/propagation.json")
+	analysis := PropagateConstants(program)
+
+	expectedConstants := map[string]map[string]Value{
+		"functions[0].body.statements[0]":                     {},
+		"functions[0].body.statements[2]":                     {"x": IntValue(10)},
+		"functions[0].body.statements[4].block.statements[2]": {"x": IntValue(10), "y": IntValue(21)},
+		// x is assigned the result of a function call in the nested block
+		"functions[0].body.statements[5]": {"y": IntValue(21)},
+		"functions[1].body.statements[0]": {},
+	}
+	for path, expected := range expectedConstants {
+		if result := analysis.ConstantsBefore(path); !reflect.DeepEqual(result, expected) {
+			t.Errorf("Unexpected constants before %v. Got %v, want %v", path, result, expected)
+		}
+	}
+	if len(analysis.Statements) != 10 {
+		t.Errorf("Unexpected number of statements. Got %v, want 10", len(analysis.Statements))
+	}
+}
+
+func TestPropagateConstants_ConstantValue(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/constants/propagation.json")
+	analysis := PropagateConstants(program)
+
+	// y = x * 2 + 1
+	value, constant := analysis.ConstantValue("functions[0].body.statements[3].operands[1].operands[0]")
+	if !constant || value != IntValue(20) {
+		t.Errorf("Unexpected value of x * 2. Got %v, %v, want 20", value, constant)
+	}
+	// z = p + y
+	if value, constant := analysis.ConstantValue("functions[0].body.statements[4].block.statements[1].operands[1]"); constant {
+		t.Errorf("Unexpected constant value of p + y: %v", value)
+	}
+}
+
+func TestFormatConstants(t *testing.T) {
+	result := FormatConstants(map[string]Value{"y": FloatValue(2.5), "x": IntValue(10), "s": StringValue("done")})
+	expected := `s = "done", x = 10, y = 2.5`
+	if result != expected {
+		t.Errorf("Unexpected result. Got %v, want %v", result, expected)
+	}
+}
@@ -0,0 +1,205 @@
+package validator
+
+import "fmt"
+
+// -----------------------------------------
+// Variable accesses
+// -----------------------------------------
+/*
+	The data flow analyses work on the sequence of variable accesses of a function in execution order.
+	Each access is resolved to the declaration it refers to using the block scopes, so variables with the same
+	name declared in different blocks are told apart.
+		- the operands of an operation and the arguments of a call are evaluated from left to right.
+		- the value of an assignment is evaluated before the variable is assigned, so in "x = x + 1" the use of x
+		comes before the assignment.
+		- function calls can't access the variables of the caller.
+*/
+
+// Declaration identifies a variable declaration or a function parameter
+type Declaration struct {
+	Function  string
+	Variable  string
+	Path      string // JSON path of the variable_declaration statement or of the parameter
+	Parameter bool   // true for function parameters
+}
+
+// accessKind is the kind of a variableAccess
+type accessKind int
+
+const (
+	accessParameter  accessKind = iota // a parameter is bound when the function is called
+	accessAssignment                   // a variable is assigned by an assignment operation
+	accessUse                          // a variable is read by a variable operand
+)
+
+// variableAccess is an access to a declared variable
+type variableAccess struct {
+	kind        accessKind
+	declaration int    // index of the declaration in functionAccesses.declarations
+	path        string // JSON path of the parameter, the assignment operation or the variable operand
+}
+
+// functionAccesses holds the declarations of a function and the accesses to them, in execution order
+type functionAccesses struct {
+	declarations []Declaration
+	accesses     []variableAccess
+}
+
+// accessCollector holds the state of collectAccesses
+type accessCollector struct {
+	function string
+	result   functionAccesses
+	// scopes maps the variables visible in each surrounding block to their declaration
+	scopes []map[string]int
+}
+
+// collectAccesses returns the declarations and the variable accesses of the function at the given index.
+// Accesses to undeclared variables are ignored.
+func collectAccesses(function Function, index int) functionAccesses {
+	collector := accessCollector{function: function.Name}
+	collector.scopes = []map[string]int{make(map[string]int)}
+	path := functionPath(index)
+	for i, param := range function.Parameters {
+		paramPath := elementPath(path, "parameters", i)
+		// a default value may use the preceding parameters
+		if param.Default != nil {
+			collector.statement(*param.Default, paramPath+".default")
+		}
+		id := collector.declare(param.Name, paramPath, true)
+		collector.access(accessParameter, id, paramPath)
+	}
+	collector.block(function.Body, path+".body")
+	return collector.result
+}
+
+func (c *accessCollector) declare(name string, path string, parameter bool) int {
+	id := len(c.result.declarations)
+	c.result.declarations = append(c.result.declarations, Declaration{Function: c.function, Variable: name, Path: path, Parameter: parameter})
+	c.scopes[len(c.scopes)-1][name] = id
+	return id
+}
+
+// resolve returns the declaration of the innermost visible variable with the given name, or -1 if there is none
+func (c *accessCollector) resolve(name string) int {
+	for i := len(c.scopes) - 1; i >= 0; i-- {
+		if id, declared := c.scopes[i][name]; declared {
+			return id
+		}
+	}
+	return -1
+}
+
+func (c *accessCollector) access(kind accessKind, declaration int, path string) {
+	if declaration >= 0 {
+		c.result.accesses = append(c.result.accesses, variableAccess{kind: kind, declaration: declaration, path: path})
+	}
+}
+
+func (c *accessCollector) block(block Block, path string) {
+	c.scopes = append(c.scopes, make(map[string]int))
+	for i, statement := range block.Statements {
+		c.statement(statement, elementPath(path, "statements", i))
+	}
+	c.scopes = c.scopes[:len(c.scopes)-1]
+}
+
+// statement collects the accesses of a statement or an operand
+func (c *accessCollector) statement(statement Statement, path string) {
+	switch statement.Type {
+	case "block":
+		c.block(statement.Block, path+".block")
+	case "variable_declaration":
+		c.declare(statement.Variable, path, false)
+	case "variable":
+		c.access(accessUse, c.resolve(statement.Variable), path)
+	case "function_call":
+		for i, arg := range statement.Arguments {
+			c.statement(arg, elementPath(path, "arguments", i))
+		}
+	case "operation":
+		if statement.OperationType == "assignment" && len(statement.Operands) == 2 {
+			c.statement(statement.Operands[1], elementPath(path, "operands", 1))
+			c.access(accessAssignment, c.resolve(statement.Operands[0].Variable), path)
+			return
+		}
+		for i, operand := range statement.Operands {
+			c.statement(operand, elementPath(path, "operands", i))
+		}
+	}
+}
+
+// -----------------------------------------
+// Use-def and def-use chains
+// -----------------------------------------
+
+// Definition is the binding of a parameter or an assignment of a variable
+type Definition struct {
+	Declaration Declaration
+	Path        string // JSON path of the parameter or of the assignment operation
+	Parameter   bool   // true for the binding of a parameter when the function is called
+}
+
+// Use is a variable operand reading a variable
+type Use struct {
+	Declaration Declaration
+	Path        string // JSON path of the variable operand
+}
+
+// UseDefChain links a use to the definitions reaching it.
+// Definitions is empty if the variable is used before being assigned.
+type UseDefChain struct {
+	Use         Use
+	Definitions []Definition
+}
+
+// DefUseChain links a definition to the uses it reaches.
+// Uses is empty if the definition is never read, see FindDeadStores.
+type DefUseChain struct {
+	Definition Definition
+	Uses       []Use
+}
+
+// FunctionChains holds the use-def and def-use chains of a function, in program order
+type FunctionChains struct {
+	Function string
+	UseDef   []UseDefChain
+	DefUse   []DefUseChain
+}
+
+// Chains computes the reaching definitions of the variables of a function, respecting the block scopes.
+// It is assumed that the program is valid, i.e. ValidateProgramRec returns true.
+func (program Program) Chains(functionName string) (FunctionChains, error) {
+	for i, function := range program.Functions {
+		if function.Name == functionName {
+			return computeChains(function, i), nil
+		}
+	}
+	return FunctionChains{}, fmt.Errorf("function: %v is not declared", functionName)
+}
+
+func computeChains(function Function, index int) FunctionChains {
+	accesses := collectAccesses(function, index)
+	chains := FunctionChains{Function: function.Name, UseDef: []UseDefChain{}, DefUse: []DefUseChain{}}
+
+	// reaching maps each declaration to the indices in chains.DefUse of the definitions reaching the current access.
+	// The language has no branches, so a definition always kills the previous ones.
+	reaching := make(map[int][]int)
+	for _, access := range accesses.accesses {
+		declaration := accesses.declarations[access.declaration]
+		switch access.kind {
+		case accessParameter, accessAssignment:
+			definition := Definition{Declaration: declaration, Path: access.path, Parameter: access.kind == accessParameter}
+			reaching[access.declaration] = []int{len(chains.DefUse)}
+			chains.DefUse = append(chains.DefUse, DefUseChain{Definition: definition, Uses: []Use{}})
+		case accessUse:
+			use := Use{Declaration: declaration, Path: access.path}
+			definitions := []Definition{}
+			for _, definition := range reaching[access.declaration] {
+				definitions = append(definitions, chains.DefUse[definition].Definition)
+				chains.DefUse[definition].Uses = append(chains.DefUse[definition].Uses, use)
+			}
+			chains.UseDef = append(chains.UseDef, UseDefChain{Use: use, Definitions: definitions})
+		}
+	}
+	return chains
+}
@@ -0,0 +1,70 @@
+package validator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChains_NestedBlocks(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/chains/nested_blocks.json")
+	chains, err := program.Chains("main")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	p := Declaration{Function: "main", Variable: "p", Path: "functions[0].parameters[0]", Parameter: true}
+	q := Declaration{Function: "main", Variable: "q", Path: "functions[0].parameters[1]", Parameter: true}
+	tVar := Declaration{Function: "main", Variable: "t", Path: "functions[0].body.statements[0]"}
+	u := Declaration{Function: "main", Variable: "u", Path: "functions[0].body.statements[2].block.statements[0]"}
+	bindP := Definition{Declaration: p, Path: "functions[0].parameters[0]", Parameter: true}
+	bindQ := Definition{Declaration: q, Path: "functions[0].parameters[1]", Parameter: true}
+	assignT := Definition{Declaration: tVar, Path: "functions[0].body.statements[1]"}
+	assignU := Definition{Declaration: u, Path: "functions[0].body.statements[2].block.statements[1]"}
+	reassignT := Definition{Declaration: tVar, Path: "functions[0].body.statements[3].block.statements[0]"}
+	incrementT := Definition{Declaration: tVar, Path: "functions[0].body.statements[3].block.statements[1]"}
+	useP := Use{Declaration: p, Path: "functions[0].parameters[1].default"}
+	useQ := Use{Declaration: q, Path: "functions[0].body.statements[1].operands[1]"}
+	useT := Use{Declaration: tVar, Path: "functions[0].body.statements[2].block.statements[1].operands[1]"}
+	useU := Use{Declaration: u, Path: "functions[0].body.statements[2].block.statements[2].arguments[0]"}
+	useReassignedT := Use{Declaration: tVar, Path: "functions[0].body.statements[3].block.statements[1].operands[1].operands[0]"}
+	useP2 := Use{Declaration: p, Path: "functions[0].body.statements[3].block.statements[1].operands[1].operands[1]"}
+	useIncrementedT := Use{Declaration: tVar, Path: "functions[0].body.statements[3].block.statements[2].arguments[0]"}
+	// the assignments of the nested block reach the statements following it
+	useLastT := Use{Declaration: tVar, Path: "functions[0].body.statements[4].arguments[0]"}
+
+	expectedUseDef := []UseDefChain{
+		{Use: useP, Definitions: []Definition{bindP}},
+		{Use: useQ, Definitions: []Definition{bindQ}},
+		{Use: useT, Definitions: []Definition{assignT}},
+		{Use: useU, Definitions: []Definition{assignU}},
+		{Use: useReassignedT, Definitions: []Definition{reassignT}},
+		{Use: useP2, Definitions: []Definition{bindP}},
+		{Use: useIncrementedT, Definitions: []Definition{incrementT}},
+		{Use: useLastT, Definitions: []Definition{incrementT}},
+	}
+	if !reflect.DeepEqual(chains.UseDef, expectedUseDef) {
+		t.Errorf("Unexpected use-def chains. Got %v, want %v", chains.UseDef, expectedUseDef)
+	}
+
+	expectedDefUse := []DefUseChain{
+		{Definition: bindP, Uses: []Use{useP, useP2}},
+		{Definition: bindQ, Uses: []Use{useQ}},
+		{Definition: assignT, Uses: []Use{useT}},
+		{Definition: assignU, Uses: []Use{useU}},
+		{Definition: reassignT, Uses: []Use{useReassignedT}},
+		{Definition: incrementT, Uses: []Use{useIncrementedT, useLastT}},
+	}
+	if !reflect.DeepEqual(chains.DefUse, expectedDefUse) {
+		t.Errorf("Unexpected def-use chains. Got %v, want %v", chains.DefUse, expectedDefUse)
+	}
+	if !ValidateProgramRec(program, false) {
+		t.Errorf("The program should be valid, the chains assume it")
+	}
+}
+
+func TestChains_UndeclaredFunction(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/chains/nested_blocks.json")
+	if _, err := program.Chains("undeclared"); err == nil {
+		t.Errorf("Expected an error for an undeclared function")
+	}
+}
@@ -0,0 +1,138 @@
+package validator
+
+import "fmt"
+
+// -----------------------------------------
+// Dead code elimination
+// -----------------------------------------
+/*
+	EliminateDeadCode repeats the following passes until nothing is removed:
+		- remove the functions which can't be reached from the entry point, see FindFunctionCalls.
+		- remove the unused variables and the dead stores, see FixUnusedVariables.
+		- remove the empty statements, i.e. {} in JSON, and the nested blocks which are empty.
+	Each pass may expose more dead code, e.g. removing the dead store "y = x" may leave x unused.
+	As in FixUnusedVariables, the statement giving the value of a function is never removed.
+*/
+
+// RemovedCode describes a function or a statement removed by EliminateDeadCode
+type RemovedCode struct {
+	Pass     int    // number of the pass removing the code, counting from 1
+	Kind     string // function, variable_declaration, assignment, block or empty_statement
+	Function string // removed function, or function containing the removed statement
+	Variable string // declared or assigned variable, for variable_declaration and assignment
+	Path     string // JSON path of the removed code in the given program
+}
+
+func (r RemovedCode) String() string {
+	if r.Kind == "function" {
+		return fmt.Sprintf("pass %v: removed function %v at %v", r.Pass, r.Function, r.Path)
+	}
+	if r.Variable != "" {
+		return fmt.Sprintf("pass %v: removed %v of %v.%v at %v", r.Pass, r.Kind, r.Function, r.Variable, r.Path)
+	}
+	return fmt.Sprintf("pass %v: removed %v of %v at %v", r.Pass, r.Kind, r.Function, r.Path)
+}
+
+// EliminateDeadCode returns a copy of the program without the dead code, along with the removed code in the order it
+// is removed. The entry function and the functions it calls, directly or not, are kept. The functions exported by a
+// module are kept as well, along with the functions they call, since other programs may call them.
+// It is assumed that the program is valid, i.e. ValidateProgramRec returns true.
+func EliminateDeadCode(program Program, entry string) (Program, []RemovedCode, error) {
+	declared := false
+	for _, function := range program.Functions {
+		declared = declared || function.Name == entry
+	}
+	if !declared {
+		return Program{}, nil, fmt.Errorf("function: %v is not declared", entry)
+	}
+
+	removed := []RemovedCode{}
+	// origins maps the paths of the program to the paths of the given program
+	var origins pathMap
+	for pass := 1; ; pass++ {
+		removedBefore := len(removed)
+
+		// unreachable functions
+		reachable := reachableFunctions(program, entry)
+		functions := []Function{}
+		paths := make(pathMap)
+		for i, function := range program.Functions {
+			if _, called := reachable[function.Name]; called {
+				path := functionPath(len(functions))
+				paths[path] = functionPath(i)
+				mapBlockPaths(function.Body, functionPath(i)+".body", path+".body", paths)
+				functions = append(functions, function)
+				continue
+			}
+			removed = append(removed, RemovedCode{Pass: pass, Kind: "function", Function: function.Name, Path: origins.original(functionPath(i))})
+		}
+		program.Functions = functions
+		origins = origins.then(paths)
+
+		// unused variables and dead stores
+		var statements []RemovedStatement
+		program, statements, paths = fixUnusedVariables(program)
+		for _, statement := range statements {
+			removed = append(removed, RemovedCode{Pass: pass, Kind: statement.Statement, Function: statement.Function, Variable: statement.Variable, Path: origins.original(statement.Path)})
+		}
+		origins = origins.then(paths)
+
+		// empty statements and blocks
+		paths = make(pathMap)
+		for i, function := range program.Functions {
+			path := functionPath(i)
+			paths[path] = path
+			result := resultPath(function.Body, path+".body")
+			program.Functions[i].Body = removeEmptyStatements(function.Body, path+".body", path+".body", result, pass, function.Name, &removed, origins, paths)
+		}
+		origins = origins.then(paths)
+
+		if len(removed) == removedBefore {
+			return program, removed, nil
+		}
+	}
+}
+
+// removeEmptyStatements returns a copy of the block without the empty statements and empty nested blocks, including
+// the blocks holding only empty statements and blocks. The statements giving the result of the function are kept.
+// The removed code is reported at its path in the program origins refers to, and the kept statements are added to
+// the paths, the copy of the block is at newPath.
+func removeEmptyStatements(block Block, path string, newPath string, result string, pass int, function string, removed *[]RemovedCode, origins pathMap, paths pathMap) Block {
+	kept := Block{Statements: []Statement{}}
+	for i, statement := range block.Statements {
+		statementPath := elementPath(path, "statements", i)
+		newStatementPath := elementPath(newPath, "statements", len(kept.Statements))
+		if statement.Type == "block" {
+			statement.Block = removeEmptyStatements(statement.Block, statementPath+".block", newStatementPath+".block", result, pass, function, removed, origins, paths)
+		}
+		isEmpty := statement.Type == "" || (statement.Type == "block" && len(statement.Block.Statements) == 0)
+		if isEmpty && !givesResult(statementPath, result) {
+			kind := "block"
+			if statement.Type == "" {
+				kind = "empty_statement"
+			}
+			*removed = append(*removed, RemovedCode{Pass: pass, Kind: kind, Function: function, Path: origins.original(statementPath)})
+			continue
+		}
+		paths[newStatementPath] = statementPath
+		kept.Statements = append(kept.Statements, statement)
+	}
+	return kept
+}
+
+// reachableFunctions returns the entry function, the functions exported by the module, and the functions they call
+func reachableFunctions(program Program, entry string) set {
+	roots := []string{entry}
+	if program.Module != "" {
+		for _, function := range program.ExportedFunctions() {
+			roots = append(roots, function.Name)
+		}
+	}
+	calls := FindFunctionCalls(program)
+	reachable := make(set)
+	for _, root := range roots {
+		reachable.add(root)
+		reachable.append(calls[root])
+	}
+	return reachable
+}
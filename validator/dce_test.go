@@ -0,0 +1,124 @@
+package validator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEliminateDeadCode(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/dce/program.json")
+
+	// y is only read by the dead store to z, so it is removed by the second pass of FixUnusedVariables.
+	// The paths refer to the given program.
+	expectedRemoved := []RemovedCode{
+		{Pass: 1, Kind: "function", Function: "unreachable", Path: "functions[1]"},
+		{Pass: 1, Kind: "function", Function: "helper", Path: "functions[3]"},
+		{Pass: 1, Kind: "variable_declaration", Function: "main", Variable: "x", Path: "functions[0].body.statements[0]"},
+		{Pass: 1, Kind: "assignment", Function: "main", Variable: "x", Path: "functions[0].body.statements[1]"},
+		{Pass: 1, Kind: "variable_declaration", Function: "main", Variable: "y", Path: "functions[0].body.statements[2]"},
+		{Pass: 1, Kind: "assignment", Function: "main", Variable: "y", Path: "functions[0].body.statements[3]"},
+		{Pass: 1, Kind: "variable_declaration", Function: "main", Variable: "z", Path: "functions[0].body.statements[4]"},
+		{Pass: 1, Kind: "assignment", Function: "main", Variable: "z", Path: "functions[0].body.statements[5]"},
+		{Pass: 1, Kind: "block", Function: "main", Path: "functions[0].body.statements[6]"},
+		{Pass: 1, Kind: "empty_statement", Function: "main", Path: "functions[0].body.statements[7].block.statements[0]"},
+		{Pass: 1, Kind: "block", Function: "main", Path: "functions[0].body.statements[7]"},
+	}
+	expectedResult := Program{Functions: []Function{
+		{Name: "main", Parameters: []Parameter{}, Body: Block{Statements: []Statement{
+			{Type: "function_call", CalledFunction: "used", Arguments: []Statement{}},
+			{Type: "function_call", CalledFunction: "used", Arguments: []Statement{}},
+		}}},
+		{Name: "used", Parameters: []Parameter{}, Body: Block{Statements: []Statement{}}},
+	}}
+
+	result, removed, err := EliminateDeadCode(program, "main")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(removed, expectedRemoved) {
+		t.Errorf("Unexpected removed code. Got %v, want %v", removed, expectedRemoved)
+	}
+	if !reflect.DeepEqual(result, expectedResult) {
+		t.Errorf("Unexpected result. Got %v, want %v", result, expectedResult)
+	}
+	if len(program.Functions) != 4 || len(program.Functions[0].Body.Statements) != 10 {
+		t.Errorf("The original program is changed")
+	}
+}
+
+func TestEliminateDeadCode_KeepsResultBlock(t *testing.T) {
+	// the value of main is the value of its empty last block
+	program := Program{Functions: []Function{{
+		Name: "main",
+		Body: Block{Statements: []Statement{{Type: "block"}, {Type: "block"}}},
+	}}}
+
+	expectedRemoved := []RemovedCode{
+		{Pass: 1, Kind: "block", Function: "main", Path: "functions[0].body.statements[0]"},
+	}
+	_, removed, err := EliminateDeadCode(program, "main")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(removed, expectedRemoved) {
+		t.Errorf("Unexpected removed code. Got %v, want %v", removed, expectedRemoved)
+	}
+}
+
+func TestEliminateDeadCode_PathsOfMovedFunction(t *testing.T) {
+	// removing dead moves main from functions[1] to functions[0]
+	program := Program{Functions: []Function{
+		{Name: "dead", Parameters: []Parameter{}, Body: Block{Statements: []Statement{}}},
+		{Name: "main", Parameters: []Parameter{}, Body: Block{Statements: []Statement{
+			{Type: "variable_declaration", Variable: "x"},
+			{},
+			{Type: "block", Block: Block{Statements: []Statement{{}}}},
+			{Type: "function_call", CalledFunction: "main", Arguments: []Statement{}},
+		}}},
+	}}
+
+	expectedRemoved := []RemovedCode{
+		{Pass: 1, Kind: "function", Function: "dead", Path: "functions[0]"},
+		{Pass: 1, Kind: "variable_declaration", Function: "main", Variable: "x", Path: "functions[1].body.statements[0]"},
+		{Pass: 1, Kind: "empty_statement", Function: "main", Path: "functions[1].body.statements[1]"},
+		{Pass: 1, Kind: "empty_statement", Function: "main", Path: "functions[1].body.statements[2].block.statements[0]"},
+		{Pass: 1, Kind: "block", Function: "main", Path: "functions[1].body.statements[2]"},
+	}
+	_, removed, err := EliminateDeadCode(program, "main")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(removed, expectedRemoved) {
+		t.Errorf("Unexpected removed code. Got %v, want %v", removed, expectedRemoved)
+	}
+}
+
+func TestEliminateDeadCode_UndeclaredEntry(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/dce/program.json")
+	if _, _, err := EliminateDeadCode(program, "start"); err == nil {
+		t.Errorf("Expected an error for an undeclared entry function")
+	}
+}
+
+func TestEliminateDeadCode_KeepsExportedFunctions(t *testing.T) {
+	// area isn't reachable from the entry, but it is exported
+	program := ReadTestCaseFromJSON("../data/modules/geometry.json")
+	program.Exports = []string{"area"}
+
+	expectedRemoved := []RemovedCode{{Pass: 1, Kind: "function", Function: "perimeter", Path: "functions[1]"}}
+	result, removed, err := EliminateDeadCode(program, "checked")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(removed, expectedRemoved) {
+		t.Errorf("Unexpected removed code. Got %v, want %v", removed, expectedRemoved)
+	}
+	if len(result.Functions) != 2 || result.Module != "geometry" {
+		t.Errorf("Unexpected result. Got %v", result)
+	}
+
+	program.Exports = nil
+	if _, removed, _ := EliminateDeadCode(program, "checked"); len(removed) != 0 {
+		t.Errorf("Unexpected removed code. Got %v, want none", removed)
+	}
+}
@@ -0,0 +1,62 @@
+package validator
+
+import "fmt"
+
+// -----------------------------------------
+// Dead stores
+// -----------------------------------------
+
+// DeadStore reports an assignment whose value is never read
+type DeadStore struct {
+	Function string
+	Variable string
+	Path     string // JSON path of the assignment operation
+	// Overwritten is true if the variable is assigned again before being read,
+	// false if it is never read after the assignment
+	Overwritten bool
+}
+
+func (d DeadStore) String() string {
+	reason := "never read"
+	if d.Overwritten {
+		reason = "overwritten before being read"
+	}
+	return fmt.Sprintf("%v.%v at %v: %v", d.Function, d.Variable, d.Path, reason)
+}
+
+// FindDeadStores reports each assignment operation whose value is overwritten or never read, in program order.
+// The liveness of each variable is computed by walking its accesses backwards from the end of the function:
+// a variable is live if its next access is a use.
+//
+// It is assumed that the program is valid, i.e. ValidateProgramRec returns true.
+func FindDeadStores(program Program) []DeadStore {
+	deadStores := []DeadStore{}
+	for i, function := range program.Functions {
+		accesses := collectAccesses(function, i)
+
+		// next holds the kind of the next access to each declaration, the variable is live if it is a use
+		next := make(map[int]accessKind)
+		functionDeadStores := []DeadStore{}
+		for j := len(accesses.accesses) - 1; j >= 0; j-- {
+			access := accesses.accesses[j]
+			if access.kind == accessAssignment {
+				nextKind, accessed := next[access.declaration]
+				if !accessed || nextKind != accessUse {
+					functionDeadStores = append(functionDeadStores, DeadStore{
+						Function:    function.Name,
+						Variable:    accesses.declarations[access.declaration].Variable,
+						Path:        access.path,
+						Overwritten: accessed,
+					})
+				}
+			}
+			next[access.declaration] = access.kind
+		}
+
+		// the dead stores are found backwards, report them in program order
+		for j := len(functionDeadStores) - 1; j >= 0; j-- {
+			deadStores = append(deadStores, functionDeadStores[j])
+		}
+	}
+	return deadStores
+}
@@ -0,0 +1,29 @@
+package validator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindDeadStores(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/dead_stores/dead_stores.json")
+
+	expectedResult := []DeadStore{
+		{Function: "main", Variable: "x", Path: "functions[0].body.statements[1]", Overwritten: true},
+		{Function: "main", Variable: "x", Path: "functions[0].body.statements[4]"},
+		{Function: "main", Variable: "z", Path: "functions[0].body.statements[7].block.statements[1]"},
+		{Function: "main", Variable: "p", Path: "functions[0].body.statements[8]"},
+	}
+	result := FindDeadStores(program)
+	if !reflect.DeepEqual(result, expectedResult) {
+		t.Errorf("Unexpected result. Got %v, want %v", result, expectedResult)
+	}
+}
+
+func TestFindDeadStores_NoDeadStores(t *testing.T) {
+	// every assigned variable is read afterwards
+	program := ReadTestCaseFromJSON("../data/valid/function_call_with_unassigned_variable_fixed.json")
+	if result := FindDeadStores(program); len(result) != 0 {
+		t.Errorf("Unexpected dead stores: %v", result)
+	}
+}
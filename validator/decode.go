@@ -0,0 +1,281 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// -----------------------------------------
+// Input formats
+// -----------------------------------------
+/*
+	Programs can be written in several formats, each with its own decoder:
+		- json: the representation described in the readme, the reference for the other formats.
+		- vl: the textual syntax read by ParseProgram.
+		- yaml and toml: the JSON representation written in YAML or TOML, see decodeYAML and decodeTOML.
+	The YAML and TOML documents are converted to JSON and decoded as such, so they produce the same Program as the
+	equivalent JSON file. Their scalars are all read as strings, e.g. value: 10 is the same as "value": "10".
+	The decoders of YAML and TOML return a SourceMap, so that the JSON paths of the diagnostics can refer to the lines
+	of the original file.
+*/
+
+var (
+	// ErrUnknownInputFormat is returned for an input format which isn't in InputFormats
+	ErrUnknownInputFormat = errors.New("unknown input format")
+	// ErrCommentsLost is returned by Rewritable for a program holding comments, which the encoded program doesn't keep
+	ErrCommentsLost = errors.New("the comments would be lost")
+)
+
+// InputFormat decodes, and possibly encodes, the programs written in a format
+type InputFormat struct {
+	Name       string
+	Extensions []string // file extensions selecting the format, with the leading dot
+	// Decode returns the program written in data, along with the position of its nodes if the format isn't JSON
+	Decode func(data []byte) (Program, SourceMap, error)
+	// Encode returns the program written in the format, nil if programs can't be written in the format
+	Encode func(program Program) []byte
+	// Rewritable returns an error if encoding the program written in data would lose information, e.g. comments.
+	// It is nil if the encoded program keeps everything.
+	Rewritable func(data []byte) error
+}
+
+var (
+	// JSONInputFormat is the JSON representation, written in its canonical form by FormatProgram
+	JSONInputFormat = InputFormat{Name: "json", Extensions: []string{".json"}, Decode: decodeJSON, Encode: FormatProgram}
+	// TextualInputFormat is the textual syntax read by ParseProgram and written by PrintProgram
+	TextualInputFormat = InputFormat{Name: "vl", Extensions: []string{".vl"}, Decode: decodeTextual, Encode: encodeTextual, Rewritable: rewritableTextual}
+	// YAMLInputFormat is the JSON representation written in YAML
+	YAMLInputFormat = InputFormat{Name: "yaml", Extensions: []string{".yaml", ".yml"}, Decode: decodeYAML}
+	// TOMLInputFormat is the JSON representation written in TOML
+	TOMLInputFormat = InputFormat{Name: "toml", Extensions: []string{".toml"}, Decode: decodeTOML}
+)
+
+// InputFormats lists the supported formats, keyed by their name
+var InputFormats = map[string]InputFormat{
+	JSONInputFormat.Name:    JSONInputFormat,
+	TextualInputFormat.Name: TextualInputFormat,
+	YAMLInputFormat.Name:    YAMLInputFormat,
+	TOMLInputFormat.Name:    TOMLInputFormat,
+}
+
+// InputFormatByName returns the supported format with the given name
+func InputFormatByName(name string) (InputFormat, error) {
+	format, ok := InputFormats[name]
+	if !ok {
+		return InputFormat{}, fmt.Errorf("%w: %v", ErrUnknownInputFormat, name)
+	}
+	return format, nil
+}
+
+// InputFormatForFile returns the format selected by the extension of the file, JSON for an unknown extension
+func InputFormatForFile(filePath string) InputFormat {
+	extension := strings.ToLower(filepath.Ext(filePath))
+	for _, format := range InputFormats {
+		for _, formatExtension := range format.Extensions {
+			if extension == formatExtension {
+				return format
+			}
+		}
+	}
+	return JSONInputFormat
+}
+
+func decodeJSON(data []byte) (Program, SourceMap, error) {
+	var program Program
+	err := json.Unmarshal(data, &program)
+	return program, nil, err
+}
+
+func decodeTextual(data []byte) (Program, SourceMap, error) {
+	program, err := ParseProgram(string(data))
+	return program, nil, err
+}
+
+func encodeTextual(program Program) []byte {
+	return []byte(PrintProgram(program, PrintOptions{}))
+}
+
+func rewritableTextual(data []byte) error {
+	comments, err := hasComments(string(data))
+	if err != nil {
+		return err
+	}
+	if comments {
+		return ErrCommentsLost
+	}
+	return nil
+}
+
+// -----------------------------------------
+// Source maps
+// -----------------------------------------
+
+// Position is a position in a file
+type Position struct {
+	Line   int // counting from 1
+	Column int // counting from 1, in bytes
+}
+
+// SourceMap maps the JSON paths of the nodes of a program, e.g. functions[0].body.statements[2], to their position
+// in the decoded file
+type SourceMap map[string]Position
+
+// jsonPathPattern matches the JSON paths used in the diagnostics
+var jsonPathPattern = regexp.MustCompile(`functions\[\d+\](\.[a-z_]+(\[\d+\])*)*`)
+
+// Annotate returns the text where each JSON path is followed by the line of its node, e.g.
+// "functions[0].body (line 3)". A path without a known position is annotated with the line of its closest parent.
+func (s SourceMap) Annotate(text string) string {
+	if len(s) == 0 {
+		return text
+	}
+	return jsonPathPattern.ReplaceAllStringFunc(text, func(path string) string {
+		for parent := path; parent != ""; parent = parentPath(parent) {
+			if position, ok := s[parent]; ok {
+				return fmt.Sprintf("%v (line %v)", path, position.Line)
+			}
+		}
+		return path
+	})
+}
+
+// parentPath returns the JSON path without its last field or index, or an empty string for a top level path
+func parentPath(path string) string {
+	end := strings.LastIndexAny(path, ".[")
+	if end < 0 {
+		return ""
+	}
+	return path[:end]
+}
+
+// -----------------------------------------
+// Decoded documents
+// -----------------------------------------
+
+// nodeKind is the kind of a sourceNode
+type nodeKind int
+
+const (
+	nodeNull nodeKind = iota
+	nodeScalar
+	nodeMapping
+	nodeSequence
+)
+
+// sourceNode is a value of a YAML or TOML document, along with its position in the file
+type sourceNode struct {
+	kind     nodeKind
+	value    string        // value of a scalar
+	keys     []string      // keys of a mapping, in order
+	values   []*sourceNode // values of a mapping, or elements of a sequence
+	position Position
+}
+
+// field returns the value of a key of a mapping, nil if the key isn't defined
+func (n *sourceNode) field(key string) *sourceNode {
+	for i, k := range n.keys {
+		if k == key {
+			return n.values[i]
+		}
+	}
+	return nil
+}
+
+// set adds a key to a mapping
+func (n *sourceNode) set(key string, value *sourceNode) {
+	n.keys = append(n.keys, key)
+	n.values = append(n.values, value)
+}
+
+// nodeSpan is the range of bytes of a node in the JSON form of a document
+type nodeSpan struct {
+	node       *sourceNode
+	start, end int
+}
+
+// decodeDocument converts a document to JSON and decodes it, so that it gives the same program as the equivalent
+// JSON file. The errors of the JSON decoder refer to the position of the node in the document.
+func decodeDocument(root *sourceNode) (Program, SourceMap, error) {
+	var buffer bytes.Buffer
+	spans := []nodeSpan{}
+	writeSourceNode(&buffer, root, &spans)
+
+	var program Program
+	if err := json.Unmarshal(buffer.Bytes(), &program); err != nil {
+		var typeError *json.UnmarshalTypeError
+		if !errors.As(err, &typeError) {
+			return Program{}, nil, err
+		}
+		// the offset of the error follows the value, the innermost node ending there is reported
+		position := root.position
+		for _, span := range spans {
+			if span.start < int(typeError.Offset) && int(typeError.Offset) <= span.end {
+				position = span.node.position
+			}
+		}
+		message := fmt.Sprintf("unexpected %v, expected %v", typeError.Value, typeError.Type)
+		return Program{}, nil, &SyntaxError{Line: position.Line, Column: position.Column, Message: message}
+	}
+
+	sourceMap := SourceMap{}
+	addSourcePaths(sourceMap, root, "")
+	return program, sourceMap, nil
+}
+
+// writeSourceNode writes the JSON form of a node, along with the span of each node in the order they start
+func writeSourceNode(buffer *bytes.Buffer, node *sourceNode, spans *[]nodeSpan) {
+	index := len(*spans)
+	*spans = append(*spans, nodeSpan{node: node, start: buffer.Len()})
+	switch node.kind {
+	case nodeNull:
+		buffer.WriteString("null")
+	case nodeScalar:
+		writeJSONString(buffer, node.value)
+	case nodeMapping:
+		buffer.WriteString("{")
+		for i, key := range node.keys {
+			if i > 0 {
+				buffer.WriteString(",")
+			}
+			writeJSONString(buffer, key)
+			buffer.WriteString(":")
+			writeSourceNode(buffer, node.values[i], spans)
+		}
+		buffer.WriteString("}")
+	case nodeSequence:
+		buffer.WriteString("[")
+		for i, element := range node.values {
+			if i > 0 {
+				buffer.WriteString(",")
+			}
+			writeSourceNode(buffer, element, spans)
+		}
+		buffer.WriteString("]")
+	}
+	(*spans)[index].end = buffer.Len()
+}
+
+// addSourcePaths adds the position of the node and of its children to the source map. The keys are matched
+// case-insensitively by the JSON decoder, so they are written in lower case as in the JSON paths.
+func addSourcePaths(sourceMap SourceMap, node *sourceNode, path string) {
+	if path != "" {
+		sourceMap[path] = node.position
+	}
+	for i, value := range node.values {
+		childPath := ""
+		switch {
+		case node.kind == nodeSequence:
+			childPath = fmt.Sprintf("%v[%d]", path, i)
+		case path == "":
+			childPath = strings.ToLower(node.keys[i])
+		default:
+			childPath = path + "." + strings.ToLower(node.keys[i])
+		}
+		addSourcePaths(sourceMap, value, childPath)
+	}
+}
@@ -0,0 +1,137 @@
+package validator
+
+import (
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestInputFormatForFile(t *testing.T) {
+	testCases := map[string]string{
+		"program.json": "json",
+		"program.vl":   "vl",
+		"program.yaml": "yaml",
+		"program.YML":  "yaml",
+		"program.toml": "toml",
+		"program":      "json",
+	}
+	for filePath, expected := range testCases {
+		if format := InputFormatForFile(filePath); format.Name != expected {
+			t.Errorf("%v: unexpected format. Got %v, want %v", filePath, format.Name, expected)
+		}
+	}
+	if _, err := InputFormatByName("xml"); !errors.Is(err, ErrUnknownInputFormat) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestDecode_SameProgramAsJSON(t *testing.T) {
+	expectedResult := ReadTestCaseFromJSON("../data/formats/program.json")
+	for _, filePath := range []string{"../data/formats/program.yaml", "../data/formats/program.toml"} {
+		data, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		program, _, err := InputFormatForFile(filePath).Decode(data)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", filePath, err)
+			continue
+		}
+		if !reflect.DeepEqual(program, expectedResult) {
+			t.Errorf("%v: unexpected result. Got\n%s\nwant\n%s", filePath, FormatProgram(program), FormatProgram(expectedResult))
+		}
+	}
+}
+
+func TestSourceMap_Annotate(t *testing.T) {
+	data, err := ioutil.ReadFile("../data/formats/program.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, sourceMap, err := decodeYAML(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	testCases := map[string]string{
+		"main.unused (local at functions[0].body.statements[0])":      "main.unused (local at functions[0].body.statements[0] (line 8))",
+		"at functions[0].body.statements[2].operands[1].arguments[1]": "at functions[0].body.statements[2].operands[1].arguments[1] (line 19)",
+		"functions[1].parameters[1].default":                          "functions[1].parameters[1].default (line 38)",
+		// an unknown path refers to the line of its closest parent
+		"functions[1].body.statements[0].operands[5]": "functions[1].body.statements[0].operands[5] (line 44)",
+		"no path": "no path",
+	}
+	for text, expected := range testCases {
+		if result := sourceMap.Annotate(text); result != expected {
+			t.Errorf("Unexpected result. Got %v, want %v", result, expected)
+		}
+	}
+	if result := SourceMap(nil).Annotate("functions[0]"); result != "functions[0]" {
+		t.Errorf("Unexpected result for an empty source map: %v", result)
+	}
+}
+
+func TestDecodeYAML_Errors(t *testing.T) {
+	testCases := []struct {
+		source   string
+		expected string
+	}{
+		{"functions:\n  - name: main\n     parameters: []", "3:6: unexpected indentation"},
+		{"functions:\n\t- name: main", "2:1: tabs can't be used for indentation"},
+		{"functions:\n  - name: main\n    name: other", "3:5: duplicate key: name"},
+		{"functions:\n  - name: \"main", "2:11: unterminated quoted scalar"},
+		{"functions: [\n  {name: main}", "2:15: expected ',' or ']' in the flow collection, found the end of the document"},
+		{"functions: [{name main}]", "1:23: expected ':' after the key name main"},
+		{"functions: |\n  text", "1:12: block scalars are not supported"},
+		{"functions: &anchor []", "1:12: anchors, aliases and tags are not supported"},
+		{"functions: []\n---\nfunctions: []", "2:1: multiple documents are not supported"},
+		{"functions:\n  - name: main\n    parameters: x", "3:17: unexpected string, expected []validator.Parameter"},
+	}
+	for _, testCase := range testCases {
+		_, _, err := decodeYAML([]byte(testCase.source))
+		if err == nil || err.Error() != testCase.expected {
+			t.Errorf("%q: unexpected error. Got %v, want %v", testCase.source, err, testCase.expected)
+		}
+	}
+}
+
+func TestDecodeTOML_Errors(t *testing.T) {
+	testCases := []struct {
+		source   string
+		expected string
+	}{
+		{"[[functions]]\nname = main", "2:8: invalid value main, strings must be quoted"},
+		{"[[functions]]\nname = \"main\"\nname = \"other\"", "3:1: duplicate key: name"},
+		{"[[functions]]\nname = \"main", "2:8: unterminated string"},
+		{"[[functions]\nname = \"main\"", "1:12: expected ']]' at the end of the table header"},
+		{"[[functions]]\nparameters = [\"a\" \"b\"]", "2:19: expected ',' or ']' in the array"},
+		{"[[functions]]\nname = \"main\" name = \"other\"", "2:15: expected a new line, found 'n'"},
+		{"functions = 1\n[[functions]]", "2:1: key functions is already defined as a value"},
+		{"[[functions]]\nname = \"\"\"main\"\"\"", "2:8: multi-line strings are not supported"},
+		{"[[functions]]\nparameters = \"x\"", "2:14: unexpected string, expected []validator.Parameter"},
+	}
+	for _, testCase := range testCases {
+		_, _, err := decodeTOML([]byte(testCase.source))
+		if err == nil || err.Error() != testCase.expected {
+			t.Errorf("%q: unexpected error. Got %v, want %v", testCase.source, err, testCase.expected)
+		}
+	}
+}
+
+func TestTextualInputFormat_Rewritable(t *testing.T) {
+	// the comments are skipped by the parser, so the encoded program would lose them
+	testCases := map[string]error{
+		"func main() {}\n":                              nil,
+		"// entry point\nfunc main() {}\n":              ErrCommentsLost,
+		"func main() {\n    1; /* result */\n}\n":       ErrCommentsLost,
+		"func main() {\n    \"// not a comment\";\n}\n": nil,
+	}
+	for source, expected := range testCases {
+		if err := TextualInputFormat.Rewritable([]byte(source)); !errors.Is(err, expected) {
+			t.Errorf("%q: unexpected error. Got %v, want %v", source, err, expected)
+		}
+		if _, err := ParseProgram(source); err != nil {
+			t.Errorf("%q: unexpected error: %v", source, err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// -----------------------------------------
+// Line diff
+// -----------------------------------------
+
+// diffContext is the number of unchanged lines shown around the changes
+const diffContext = 3
+
+// diffLine is a line of a diff, prefixed by ' ' if unchanged, '-' if removed or '+' if added
+type diffLine struct {
+	prefix byte
+	text   string
+	old    int // line number in the old text, counting from 0
+	new    int // line number in the new text, counting from 0
+}
+
+// UnifiedDiff returns the changes between two texts in the unified diff format, or an empty string if they are equal.
+// The lines are matched using their longest common subsequence.
+func UnifiedDiff(oldName string, newName string, oldText string, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	oldLines := diffLines(oldText)
+	newLines := diffLines(newText)
+
+	// common[i][j] is the length of the longest common subsequence of oldLines[i:] and newLines[j:]
+	common := make([][]int, len(oldLines)+1)
+	for i := range common {
+		common[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else if common[i+1][j] >= common[i][j+1] {
+				common[i][j] = common[i+1][j]
+			} else {
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+
+	lines := []diffLine{}
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		switch {
+		case i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j]:
+			lines = append(lines, diffLine{' ', oldLines[i], i, j})
+			i++
+			j++
+		case j == len(newLines) || (i < len(oldLines) && common[i+1][j] >= common[i][j+1]):
+			lines = append(lines, diffLine{'-', oldLines[i], i, j})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', newLines[j], i, j})
+			j++
+		}
+	}
+
+	var diff strings.Builder
+	fmt.Fprintf(&diff, "--- %v\n+++ %v\n", oldName, newName)
+	for start := 0; start < len(lines); {
+		if lines[start].prefix == ' ' {
+			start++
+			continue
+		}
+		// a hunk starts diffContext lines before a change, and ends diffContext lines after the last change
+		// which is followed by less than 2*diffContext unchanged lines
+		first := start - diffContext
+		if first < 0 {
+			first = 0
+		}
+		end := start
+		for unchanged := 0; end < len(lines) && unchanged <= 2*diffContext; end++ {
+			if lines[end].prefix == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+		}
+		last := end
+		for last > start && lines[last-1].prefix == ' ' {
+			last--
+		}
+		last += diffContext
+		if last > len(lines) {
+			last = len(lines)
+		}
+
+		oldCount, newCount := 0, 0
+		for _, line := range lines[first:last] {
+			if line.prefix != '+' {
+				oldCount++
+			}
+			if line.prefix != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&diff, "@@ -%v,%v +%v,%v @@\n", hunkStart(lines[first].old, oldCount), oldCount, hunkStart(lines[first].new, newCount), newCount)
+		for _, line := range lines[first:last] {
+			fmt.Fprintf(&diff, "%c%v\n", line.prefix, line.text)
+		}
+		start = last
+	}
+	return diff.String()
+}
+
+// diffLines splits a text into lines. A last line without a new line is marked as such, so that it differs from
+// the same line followed by a new line.
+func diffLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if !strings.HasSuffix(text, "\n") {
+		lines[len(lines)-1] += "\n\\ No newline at end of file"
+	}
+	return lines
+}
+
+// hunkStart returns the line number of a hunk in the unified diff format, counting from 1, or 0 for an empty hunk
+func hunkStart(line int, count int) int {
+	if count == 0 {
+		return line
+	}
+	return line + 1
+}
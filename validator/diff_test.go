@@ -0,0 +1,28 @@
+package validator
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	oldText := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"
+	newText := "a\nc\nd\ne\nf\ng\nh\ni\nj\nk\nL\nm\nn\n"
+
+	expectedResult := "--- old\n+++ new\n" +
+		"@@ -1,5 +1,4 @@\n a\n-b\n c\n d\n e\n" +
+		"@@ -9,5 +8,6 @@\n i\n j\n k\n-l\n+L\n m\n+n\n"
+	if result := UnifiedDiff("old", "new", oldText, newText); result != expectedResult {
+		t.Errorf("Unexpected result. Got\n%v\nwant\n%v", result, expectedResult)
+	}
+}
+
+func TestUnifiedDiff_EqualTexts(t *testing.T) {
+	if result := UnifiedDiff("old", "new", "a\nb\n", "a\nb\n"); result != "" {
+		t.Errorf("Unexpected result. Got %v, want an empty diff", result)
+	}
+}
+
+func TestUnifiedDiff_MissingNewLine(t *testing.T) {
+	expectedResult := "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n"
+	if result := UnifiedDiff("old", "new", "a\nb", "a\nb\n"); result != expectedResult {
+		t.Errorf("Unexpected result. Got\n%v\nwant\n%v", result, expectedResult)
+	}
+}
@@ -0,0 +1,184 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// -----------------------------------------
+// Fix unused variables
+// -----------------------------------------
+/*
+	FixUnusedVariables repeats the following pass until nothing is removed, using the scoped variable accesses and
+	FindDeadStores:
+		- the assignment statements whose value is never read, unless the assigned value contains a function call, which
+		may have side effects, or an assignment.
+		- the declarations of the unused local variables, once all their assignments are removed.
+	Each pass may expose more dead code, e.g. removing the dead store "y = x" may leave x unused, so that fixing the
+	fixed program again doesn't change it.
+	The statement giving the value of a function, i.e. its last statement, is never removed since it is the result of
+	the function calls. Unused parameters are never removed, since removing them would change the calls.
+*/
+
+// RemovedStatement describes a statement removed by FixUnusedVariables
+type RemovedStatement struct {
+	Function  string
+	Variable  string
+	Path      string // JSON path of the statement in the original program
+	Statement string // variable_declaration or assignment
+}
+
+func (r RemovedStatement) String() string {
+	return fmt.Sprintf("%v.%v: removed %v at %v", r.Function, r.Variable, r.Statement, r.Path)
+}
+
+// FixUnusedVariables returns a copy of the program without the unused variables and the dead stores, along with the
+// removed statements in program order. The given program is left unchanged.
+// It is assumed that the program is valid, i.e. ValidateProgramRec returns true.
+func FixUnusedVariables(program Program) (Program, []RemovedStatement) {
+	fixed, removed, _ := fixUnusedVariables(program)
+	return fixed, removed
+}
+
+// fixUnusedVariables runs the passes of FixUnusedVariables, it also returns the map from the paths of the fixed
+// program to the paths of the given one
+func fixUnusedVariables(program Program) (Program, []RemovedStatement, pathMap) {
+	removed := []RemovedStatement{}
+	var paths pathMap
+	for {
+		fixed, removedByPass, passPaths := fixUnusedVariablesPass(program)
+		if len(removedByPass) == 0 {
+			sort.SliceStable(removed, func(i, j int) bool {
+				return lessPath(removed[i].Path, removed[j].Path)
+			})
+			return program, removed, paths
+		}
+		for _, statement := range removedByPass {
+			statement.Path = paths.original(statement.Path)
+			removed = append(removed, statement)
+		}
+		paths = paths.then(passPaths)
+		program = fixed
+	}
+}
+
+// fixUnusedVariablesPass runs a single pass of FixUnusedVariables
+func fixUnusedVariablesPass(program Program) (Program, []RemovedStatement, pathMap) {
+	deadStores := make(set)
+	for _, deadStore := range FindDeadStores(program) {
+		deadStores.add(deadStore.Path)
+	}
+
+	fixed := program
+	fixed.Functions = make([]Function, len(program.Functions))
+	removed := []RemovedStatement{}
+	paths := make(pathMap)
+	for i, function := range program.Functions {
+		path := functionPath(i)
+		statements := make(map[string]Statement)
+		collectStatements(function.Body, path+".body", statements)
+		result := resultPath(function.Body, path+".body")
+
+		// remove the pure dead stores
+		remove := make(map[string]RemovedStatement)
+		for statementPath, statement := range statements {
+			_, dead := deadStores[statementPath]
+			if dead && !givesResult(statementPath, result) && statement.OperationType == "assignment" && len(statement.Operands) == 2 && isPure(statement.Operands[1]) {
+				remove[statementPath] = RemovedStatement{Function: function.Name, Variable: statement.Operands[0].Variable, Path: statementPath, Statement: "assignment"}
+			}
+		}
+
+		// remove the declarations of the unused variables whose assignments are all removed
+		accesses := collectAccesses(function, i)
+		removable := make([]bool, len(accesses.declarations))
+		for id, declaration := range accesses.declarations {
+			removable[id] = !declaration.Parameter && !givesResult(declaration.Path, result)
+		}
+		for _, access := range accesses.accesses {
+			if _, isRemoved := remove[access.path]; access.kind == accessUse || (access.kind == accessAssignment && !isRemoved) {
+				removable[access.declaration] = false
+			}
+		}
+		for id, declaration := range accesses.declarations {
+			if removable[id] {
+				remove[declaration.Path] = RemovedStatement{Function: function.Name, Variable: declaration.Variable, Path: declaration.Path, Statement: "variable_declaration"}
+			}
+		}
+
+		fixed.Functions[i] = function
+		fixed.Functions[i].Body = removeStatements(function.Body, path+".body", path+".body", remove, &removed, paths)
+		paths[path] = path
+	}
+	return fixed, removed, paths
+}
+
+// collectStatements maps the JSON path of each statement of a block and of its nested blocks to the statement
+func collectStatements(block Block, path string, statements map[string]Statement) {
+	for i, statement := range block.Statements {
+		statementPath := elementPath(path, "statements", i)
+		statements[statementPath] = statement
+		if statement.Type == "block" {
+			collectStatements(statement.Block, statementPath+".block", statements)
+		}
+	}
+}
+
+// resultPath returns the JSON path of the statement giving the value of a block, i.e. its last statement,
+// or the last statement of the last nested block. It returns an empty string for an empty block.
+func resultPath(block Block, path string) string {
+	if len(block.Statements) == 0 {
+		return ""
+	}
+	last := len(block.Statements) - 1
+	statementPath := elementPath(path, "statements", last)
+	if block.Statements[last].Type == "block" && len(block.Statements[last].Block.Statements) > 0 {
+		return resultPath(block.Statements[last].Block, statementPath+".block")
+	}
+	return statementPath
+}
+
+// givesResult returns true if the statement at the given path gives the value of the function, i.e. it is the
+// statement at the result path or one of the blocks containing it. Such statements are kept by FixUnusedVariables
+// and EliminateDeadCode.
+func givesResult(path string, result string) bool {
+	return strings.HasPrefix(result, path) && (len(result) == len(path) || result[len(path)] == '.')
+}
+
+// isPure returns true if evaluating the operand has no side effect, i.e. it contains no function call nor assignment
+func isPure(operand Statement) bool {
+	switch operand.Type {
+	case "function_call":
+		return false
+	case "operation":
+		if operand.OperationType == "assignment" {
+			return false
+		}
+		for _, nested := range operand.Operands {
+			if !isPure(nested) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// removeStatements returns a copy of the block without the statements to remove, appending them to removed.
+// The kept statements are added to the paths, the copy of the block is at newPath.
+func removeStatements(block Block, path string, newPath string, remove map[string]RemovedStatement, removed *[]RemovedStatement, paths pathMap) Block {
+	fixed := Block{Statements: []Statement{}}
+	for i, statement := range block.Statements {
+		statementPath := elementPath(path, "statements", i)
+		if removedStatement, ok := remove[statementPath]; ok {
+			*removed = append(*removed, removedStatement)
+			continue
+		}
+		newStatementPath := elementPath(newPath, "statements", len(fixed.Statements))
+		paths[newStatementPath] = statementPath
+		if statement.Type == "block" {
+			statement.Block = removeStatements(statement.Block, statementPath+".block", newStatementPath+".block", remove, removed, paths)
+		}
+		fixed.Statements = append(fixed.Statements, statement)
+	}
+	return fixed
+}
@@ -0,0 +1,95 @@
+package validator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFixUnusedVariables(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/fix/unused_and_dead_stores.json")
+
+	expectedRemoved := []RemovedStatement{
+		{Function: "main", Variable: "unused", Path: "functions[0].body.statements[0]", Statement: "variable_declaration"},
+		{Function: "main", Variable: "t", Path: "functions[0].body.statements[2]", Statement: "assignment"},
+		{Function: "main", Variable: "s", Path: "functions[0].body.statements[7]", Statement: "variable_declaration"},
+		{Function: "main", Variable: "s", Path: "functions[0].body.statements[8]", Statement: "assignment"},
+	}
+	fixed, removed := FixUnusedVariables(program)
+	if !reflect.DeepEqual(removed, expectedRemoved) {
+		t.Errorf("Unexpected removed statements. Got %v, want %v", removed, expectedRemoved)
+	}
+
+	// the assignment of a function call result is kept along with its declaration
+	expectedTypes := []string{"variable_declaration", "operation", "function_call", "variable_declaration", "operation", "function_call"}
+	types := []string{}
+	for _, statement := range fixed.Functions[0].Body.Statements {
+		types = append(types, statement.Type)
+	}
+	if !reflect.DeepEqual(types, expectedTypes) {
+		t.Errorf("Unexpected statements. Got %v, want %v", types, expectedTypes)
+	}
+	if !ValidateProgramRec(fixed, false) {
+		t.Errorf("The fixed program is not valid")
+	}
+	if len(program.Functions[0].Body.Statements) != 10 {
+		t.Errorf("The original program is changed")
+	}
+}
+
+func TestFixUnusedVariables_KeepsResultStatement(t *testing.T) {
+	// the value of main is the value of its last statement
+	program := Program{Functions: []Function{{
+		Name: "main",
+		Body: Block{Statements: []Statement{
+			{Type: "variable_declaration", Variable: "x"},
+			{Type: "block", Block: Block{Statements: []Statement{{Type: "variable_declaration", Variable: "y"}}}},
+		}},
+	}}}
+
+	expectedRemoved := []RemovedStatement{
+		{Function: "main", Variable: "x", Path: "functions[0].body.statements[0]", Statement: "variable_declaration"},
+	}
+	_, removed := FixUnusedVariables(program)
+	if !reflect.DeepEqual(removed, expectedRemoved) {
+		t.Errorf("Unexpected removed statements. Got %v, want %v", removed, expectedRemoved)
+	}
+}
+
+func TestFixUnusedVariables_Cascade(t *testing.T) {
+	// removing the dead store y = x leaves x = 1 dead, which is removed by the next pass
+	program := Program{Functions: []Function{
+		{Name: "main", Parameters: []Parameter{}, Body: Block{Statements: []Statement{
+			{Type: "variable_declaration", Variable: "x"},
+			{Type: "operation", OperationType: "assignment", Operands: []Statement{{Type: "variable", Variable: "x"}, {Type: "numerical", Value: "1"}}},
+			{Type: "block", Block: Block{Statements: []Statement{
+				{Type: "variable_declaration", Variable: "y"},
+				{Type: "operation", OperationType: "assignment", Operands: []Statement{{Type: "variable", Variable: "y"}, {Type: "variable", Variable: "x"}}},
+			}}},
+			{Type: "function_call", CalledFunction: "done", Arguments: []Statement{}},
+		}}},
+		{Name: "done", Parameters: []Parameter{}, Body: Block{Statements: []Statement{}}},
+	}}
+
+	expectedRemoved := []RemovedStatement{
+		{Function: "main", Variable: "x", Path: "functions[0].body.statements[0]", Statement: "variable_declaration"},
+		{Function: "main", Variable: "x", Path: "functions[0].body.statements[1]", Statement: "assignment"},
+		{Function: "main", Variable: "y", Path: "functions[0].body.statements[2].block.statements[0]", Statement: "variable_declaration"},
+		{Function: "main", Variable: "y", Path: "functions[0].body.statements[2].block.statements[1]", Statement: "assignment"},
+	}
+	fixed, removed := FixUnusedVariables(program)
+	if !reflect.DeepEqual(removed, expectedRemoved) {
+		t.Errorf("Unexpected removed statements. Got %v, want %v", removed, expectedRemoved)
+	}
+	expectedStatements := []Statement{
+		{Type: "block", Block: Block{Statements: []Statement{}}},
+		{Type: "function_call", CalledFunction: "done", Arguments: []Statement{}},
+	}
+	if !reflect.DeepEqual(fixed.Functions[0].Body.Statements, expectedStatements) {
+		t.Errorf("Unexpected statements. Got %v, want %v", fixed.Functions[0].Body.Statements, expectedStatements)
+	}
+
+	// the fix is idempotent
+	if _, removed := FixUnusedVariables(fixed); len(removed) != 0 {
+		t.Errorf("Unexpected statements removed by a second fix: %v", removed)
+	}
+}
@@ -0,0 +1,191 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// -----------------------------------------
+// Canonical JSON format
+// -----------------------------------------
+/*
+	FormatProgram writes a program in its canonical JSON form:
+		- the keys are the lowercase field names, in a fixed order depending on the statement type, e.g. type,
+		operation_type, operands for an operation.
+		- only the fields relevant to the statement type are written, e.g. a variable_declaration has no block.
+		- the parameters without a default value are written as plain strings.
+		- the module, its imports and its exports precede the functions, the exports are written even if the list is
+		empty, as they differ from the omitted list exporting every function.
+		- the values are indented with 4 spaces, and the output ends with a new line.
+*/
+
+// formatIndent is the indentation of the canonical JSON form
+const formatIndent = "    "
+
+// jsonField is a key and its value in a jsonObject
+type jsonField struct {
+	key   string
+	value interface{} // string, jsonObject or []interface{}
+}
+
+// jsonObject is a JSON object whose keys are written in order
+type jsonObject []jsonField
+
+// FormatProgram returns the canonical JSON form of the program
+func FormatProgram(program Program) []byte {
+	functions := make([]interface{}, len(program.Functions))
+	for i, function := range program.Functions {
+		functions[i] = formatFunction(function)
+	}
+	object := jsonObject{}
+	if program.Module != "" {
+		object = append(object, jsonField{"module", program.Module})
+	}
+	if len(program.Imports) > 0 {
+		object = append(object, jsonField{"imports", formatNames(program.Imports)})
+	}
+	if program.Exports != nil {
+		object = append(object, jsonField{"exports", formatNames(program.Exports)})
+	}
+	object = append(object, jsonField{"functions", functions})
+	var buffer bytes.Buffer
+	writeJSON(&buffer, object, 0)
+	buffer.WriteString("\n")
+	return buffer.Bytes()
+}
+
+func formatNames(names []string) []interface{} {
+	formatted := make([]interface{}, len(names))
+	for i, name := range names {
+		formatted[i] = name
+	}
+	return formatted
+}
+
+func formatFunction(function Function) jsonObject {
+	parameters := make([]interface{}, len(function.Parameters))
+	for i, param := range function.Parameters {
+		if param.Default == nil {
+			parameters[i] = param.Name
+		} else {
+			parameters[i] = jsonObject{{"name", param.Name}, {"default", formatStatement(*param.Default)}}
+		}
+	}
+	object := jsonObject{{"name", function.Name}}
+	if function.Visibility != "" {
+		object = append(object, jsonField{"visibility", function.Visibility})
+	}
+	return append(object, jsonField{"parameters", parameters}, jsonField{"body", formatBlock(function.Body)})
+}
+
+func formatBlock(block Block) jsonObject {
+	return jsonObject{{"statements", formatStatements(block.Statements)}}
+}
+
+func formatStatements(statements []Statement) []interface{} {
+	formatted := make([]interface{}, len(statements))
+	for i, statement := range statements {
+		formatted[i] = formatStatement(statement)
+	}
+	return formatted
+}
+
+// formatStatement returns the fields of a statement or an operand relevant to its type
+func formatStatement(statement Statement) jsonObject {
+	object := jsonObject{}
+	if statement.Type != "" {
+		object = append(object, jsonField{"type", statement.Type})
+	}
+	switch statement.Type {
+	case "":
+		// empty statement
+	case "block":
+		object = append(object, jsonField{"block", formatBlock(statement.Block)})
+	case "variable_declaration", "variable":
+		object = append(object, jsonField{"variable", statement.Variable})
+	case "numerical", "string", "boolean":
+		object = append(object, jsonField{"value", statement.Value})
+	case "operation":
+		object = append(object, jsonField{"operation_type", statement.OperationType}, jsonField{"operands", formatStatements(statement.Operands)})
+	case "function_call":
+		object = append(object, jsonField{"called_function", statement.CalledFunction}, jsonField{"arguments", formatStatements(statement.Arguments)})
+	default:
+		// unknown statement types keep all their fields, so that no information is lost
+		if statement.Value != "" {
+			object = append(object, jsonField{"value", statement.Value})
+		}
+		if statement.Variable != "" {
+			object = append(object, jsonField{"variable", statement.Variable})
+		}
+		if statement.Block.Statements != nil {
+			object = append(object, jsonField{"block", formatBlock(statement.Block)})
+		}
+		if statement.OperationType != "" {
+			object = append(object, jsonField{"operation_type", statement.OperationType})
+		}
+		if statement.Operands != nil {
+			object = append(object, jsonField{"operands", formatStatements(statement.Operands)})
+		}
+		if statement.CalledFunction != "" {
+			object = append(object, jsonField{"called_function", statement.CalledFunction})
+		}
+		if statement.Arguments != nil {
+			object = append(object, jsonField{"arguments", formatStatements(statement.Arguments)})
+		}
+	}
+	if statement.ParameterName != "" {
+		object = append(object, jsonField{"parameter_name", statement.ParameterName})
+	}
+	return object
+}
+
+// writeJSON writes an indented value, depth is the indentation level of the line holding the value
+func writeJSON(buffer *bytes.Buffer, value interface{}, depth int) {
+	switch value := value.(type) {
+	case string:
+		writeJSONString(buffer, value)
+	case jsonObject:
+		if len(value) == 0 {
+			buffer.WriteString("{}")
+			return
+		}
+		buffer.WriteString("{\n")
+		for i, field := range value {
+			buffer.WriteString(strings.Repeat(formatIndent, depth+1))
+			writeJSONString(buffer, field.key)
+			buffer.WriteString(": ")
+			writeJSON(buffer, field.value, depth+1)
+			if i < len(value)-1 {
+				buffer.WriteString(",")
+			}
+			buffer.WriteString("\n")
+		}
+		buffer.WriteString(strings.Repeat(formatIndent, depth) + "}")
+	case []interface{}:
+		if len(value) == 0 {
+			buffer.WriteString("[]")
+			return
+		}
+		buffer.WriteString("[\n")
+		for i, element := range value {
+			buffer.WriteString(strings.Repeat(formatIndent, depth+1))
+			writeJSON(buffer, element, depth+1)
+			if i < len(value)-1 {
+				buffer.WriteString(",")
+			}
+			buffer.WriteString("\n")
+		}
+		buffer.WriteString(strings.Repeat(formatIndent, depth) + "]")
+	}
+}
+
+// writeJSONString writes a quoted string, without escaping the HTML characters as json.Marshal does
+func writeJSONString(buffer *bytes.Buffer, value string) {
+	var quoted bytes.Buffer
+	encoder := json.NewEncoder(&quoted)
+	encoder.SetEscapeHTML(false)
+	// encoding a string can't fail
+	encoder.Encode(value)
+	buffer.Write(bytes.TrimSuffix(quoted.Bytes(), []byte("\n")))
+}
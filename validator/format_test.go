@@ -0,0 +1,94 @@
+package validator
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestFormatProgram(t *testing.T) {
+	// the keys are matched case-insensitively, and the fields irrelevant to the statement type are dropped
+	input := `{"functions": [{"name": "main", "parameters": ["a", {"name": "b", "default": {"value": "1", "type": "numerical"}}],
+		"body": {"statements": [
+			{"variable": "x", "type": "variable_declaration", "value": "ignored"},
+			{"type": "operation", "Operands": [{"type": "variable", "variable": "x"}, {"type": "string", "value": "a<b"}], "operation_type": "assignment"},
+			{"arguments": [{"type": "variable", "variable": "x", "parameter_name": "a"}], "type": "function_call", "called_function": "main"},
+			{}
+		]}}]}`
+	expectedResult := `{
+    "functions": [
+        {
+            "name": "main",
+            "parameters": [
+                "a",
+                {
+                    "name": "b",
+                    "default": {
+                        "type": "numerical",
+                        "value": "1"
+                    }
+                }
+            ],
+            "body": {
+                "statements": [
+                    {
+                        "type": "variable_declaration",
+                        "variable": "x"
+                    },
+                    {
+                        "type": "operation",
+                        "operation_type": "assignment",
+                        "operands": [
+                            {
+                                "type": "variable",
+                                "variable": "x"
+                            },
+                            {
+                                "type": "string",
+                                "value": "a<b"
+                            }
+                        ]
+                    },
+                    {
+                        "type": "function_call",
+                        "called_function": "main",
+                        "arguments": [
+                            {
+                                "type": "variable",
+                                "variable": "x",
+                                "parameter_name": "a"
+                            }
+                        ]
+                    },
+                    {}
+                ]
+            }
+        }
+    ]
+}
+`
+	var program Program
+	if err := json.Unmarshal([]byte(input), &program); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result := string(FormatProgram(program)); result != expectedResult {
+		t.Errorf("Unexpected result.\n%v", UnifiedDiff("want", "got", expectedResult, result))
+	}
+}
+
+func TestFormatProgram_DataIsFormatted(t *testing.T) {
+	files, err := filepath.Glob("../data/*/*.json")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Can't list the data files: %v", err)
+	}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Can't read %v: %v", file, err)
+		}
+		if formatted := string(FormatProgram(ReadTestCaseFromJSON(file))); formatted != string(data) {
+			t.Errorf("%v is not formatted, run -mode fmt:\n%v", file, UnifiedDiff(file, file, string(data), formatted))
+		}
+	}
+}
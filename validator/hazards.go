@@ -0,0 +1,110 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+)
+
+// -----------------------------------------
+// Arithmetic hazards
+// -----------------------------------------
+
+// Hazard reports an operation which fails whenever it is executed
+type Hazard struct {
+	Function string
+	Path     string // JSON path of the operation
+	Err      error  // wraps ErrDivisionByZero, ErrIntegerOverflow or ErrFloatOverflow
+}
+
+func (h Hazard) Error() string {
+	return fmt.Sprintf("%v (function: %v, at %v)", h.Err, h.Function, h.Path)
+}
+
+// CheckArithmeticHazards uses the constant propagation to report:
+//   - division and modulo operations whose divisor is the literal 0, or a variable or operation provably equal to 0.
+//   - operations on constants overflowing int64, when the policy requires integers.
+//   - operations on constants overflowing float64, when the policy rejects non finite values.
+//
+// It is assumed that the program is valid, i.e. ValidateProgramRec returns true.
+func CheckArithmeticHazards(program Program, policy NumericPolicy) []Hazard {
+	checker := hazardChecker{constants: PropagateConstants(program), policy: policy, hazards: []Hazard{}}
+	for i, function := range program.Functions {
+		checker.function = function.Name
+		checker.block(function.Body, functionPath(i)+".body")
+	}
+	return checker.hazards
+}
+
+// hazardChecker holds the state of CheckArithmeticHazards
+type hazardChecker struct {
+	constants ConstantAnalysis
+	policy    NumericPolicy
+	function  string
+	hazards   []Hazard
+}
+
+func (c *hazardChecker) block(block Block, path string) {
+	for i, statement := range block.Statements {
+		c.operand(statement, elementPath(path, "statements", i))
+	}
+}
+
+// operand checks an operation, and the operations nested in statements and operands
+func (c *hazardChecker) operand(operand Statement, path string) {
+	switch operand.Type {
+	case "block":
+		c.block(operand.Block, path+".block")
+	case "function_call":
+		for i, arg := range operand.Arguments {
+			c.operand(arg, elementPath(path, "arguments", i))
+		}
+	case "operation":
+		for i, nested := range operand.Operands {
+			c.operand(nested, elementPath(path, "operands", i))
+		}
+		c.operation(operand, path)
+	}
+}
+
+func (c *hazardChecker) operation(operation Statement, path string) {
+	if operation.OperationType == "division" || operation.OperationType == "modulo" {
+		if len(operation.Operands) == 2 {
+			divisor, constant := c.constants.ConstantValue(elementPath(path, "operands", 1))
+			if constant && divisor.IsNumeric() && divisor.AsFloat() == 0 {
+				c.report(path, fmt.Errorf("%w: %v by %v", ErrDivisionByZero, operation.OperationType, describeOperand(operation.Operands[1], divisor)))
+				return
+			}
+		}
+	}
+
+	if operation.OperationType == "assignment" || (!c.policy.RequireInteger && !c.policy.RejectNonFinite) {
+		return
+	}
+	values := make([]Value, len(operation.Operands))
+	for i := range operation.Operands {
+		value, constant := c.constants.ConstantValue(elementPath(path, "operands", i))
+		if !constant {
+			return
+		}
+		values[i] = value
+	}
+	_, err := EvaluateOperation(operation.OperationType, values)
+	if (c.policy.RequireInteger && errors.Is(err, ErrIntegerOverflow)) || (c.policy.RejectNonFinite && errors.Is(err, ErrFloatOverflow)) {
+		c.report(path, err)
+	}
+}
+
+// describeOperand describes a constant operand in diagnostics, e.g. "variable x = 0"
+func describeOperand(operand Statement, value Value) string {
+	switch operand.Type {
+	case "variable":
+		return fmt.Sprintf("variable %v = %v", operand.Variable, value)
+	case "numerical":
+		return operand.Value
+	}
+	return fmt.Sprintf("%v = %v", operand.Type, value)
+}
+
+func (c *hazardChecker) report(path string, err error) {
+	c.hazards = append(c.hazards, Hazard{Function: c.function, Path: path, Err: err})
+}
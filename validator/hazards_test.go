@@ -0,0 +1,56 @@
+package validator
+
+import (
+	"errors"
+	"testing"
+)
+
+// checkHazardsTestCase a helper function to check the hazards of a test case
+func checkHazardsTestCase(t *testing.T, filepath string, policy NumericPolicy, expectedErr error, expectedPath string) {
+	program := ReadTestCaseFromJSON(filepath)
+	hazards := CheckArithmeticHazards(program, policy)
+
+	if expectedErr == nil {
+		if len(hazards) != 0 {
+			t.Errorf("%v: unexpected hazards %v", filepath, hazards)
+		}
+		return
+	}
+	if len(hazards) != 1 || !errors.Is(hazards[0].Err, expectedErr) || hazards[0].Path != expectedPath {
+		t.Errorf("%v: unexpected hazards. Got %v, want %v at %v", filepath, hazards, expectedErr, expectedPath)
+	}
+}
+
+func TestCheckArithmeticHazards_DivisionByLiteralZero(t *testing.T) {
+	expectedPath := "functions[0].body.statements[1].operands[1]"
+	checkHazardsTestCase(t, "../data/hazards/division_by_literal_zero.json", PermissiveNumericPolicy, ErrDivisionByZero, expectedPath)
+}
+
+func TestCheckArithmeticHazards_ModuloByConstantZero(t *testing.T) {
+	expectedPath := "functions[0].body.statements[3].operands[1]"
+	checkHazardsTestCase(t, "../data/hazards/modulo_by_constant_zero.json", PermissiveNumericPolicy, ErrDivisionByZero, expectedPath)
+}
+
+func TestCheckArithmeticHazards_DivisionByReassignedVariable(t *testing.T) {
+	// the divisor is only zero until it is reassigned a value depending on a parameter
+	checkHazardsTestCase(t, "../data/hazards/division_by_reassigned_variable.json", IntegerNumericPolicy, nil, "")
+}
+
+func TestCheckArithmeticHazards_ConstantOverflow(t *testing.T) {
+	expectedPath := "functions[0].body.statements[3].operands[1]"
+	checkHazardsTestCase(t, "../data/hazards/constant_overflow.json", IntegerNumericPolicy, ErrIntegerOverflow, expectedPath)
+	// overflows are only reported when the policy requires integers
+	checkHazardsTestCase(t, "../data/hazards/constant_overflow.json", FiniteNumericPolicy, nil, "")
+}
+
+func TestValidateProgram_Hazards(t *testing.T) {
+	// the hazards are reported by CheckArithmeticHazards, they don't make a program invalid
+	program := ReadTestCaseFromJSON("../data/hazards/division_by_literal_zero.json")
+	if !ValidateProgramRec(program, false) {
+		t.Errorf("Expected a division by zero to be valid")
+	}
+	program = ReadTestCaseFromJSON("../data/hazards/constant_overflow.json")
+	if !ValidateProgram(program, IntegerNumericPolicy, false) {
+		t.Errorf("Expected an overflow to be valid with the integer policy")
+	}
+}
@@ -0,0 +1,157 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// -----------------------------------------
+// Function inlining
+// -----------------------------------------
+/*
+	InlineFunction replaces each function_call statement to the callee by a block which:
+		- declares a fresh variable for each parameter of the callee.
+		- assigns the arguments to the parameters in the order of the call, then the default values of the omitted
+		parameters in the order of the parameters.
+		- executes the body of the callee, where the parameters and the local variables use their fresh names.
+	The value of the block is the value of its last statement, i.e. the value of the call. The block inlining a
+	function with an empty body ends with an empty statement, since the call is void.
+	The calls nested in operands and arguments are kept, since an operand can't hold a block.
+*/
+
+// ErrRecursiveFunction is returned when inlining a function which calls itself, directly or through other functions
+var ErrRecursiveFunction = errors.New("recursive function")
+
+// InlinedCall describes a function_call statement replaced by InlineFunction
+type InlinedCall struct {
+	Function string // function containing the call
+	Path     string // JSON path of the call statement in the original program
+}
+
+// InlineFunction returns a copy of the program where every function_call statement to the callee is replaced by the
+// body of the callee, along with the inlined calls in program order. The callee itself is kept.
+// Fresh variable names are made of the callee name and the variable name, e.g. add_x, followed by a number if
+// the name is already used by the calling function.
+// It is assumed that the program is valid, i.e. ValidateProgramRec returns true.
+func InlineFunction(program Program, callee string) (Program, []InlinedCall, error) {
+	index := -1
+	for i, function := range program.Functions {
+		if function.Name == callee {
+			index = i
+		}
+	}
+	if index < 0 {
+		return Program{}, nil, fmt.Errorf("function: %v is not declared", callee)
+	}
+	if _, recursive := FindFunctionCalls(program)[callee][callee]; recursive {
+		return Program{}, nil, fmt.Errorf("%w: %v calls itself", ErrRecursiveFunction, callee)
+	}
+
+	inliner := functionInliner{callee: program.Functions[index], inlined: []InlinedCall{}}
+	inliner.variables = collectAccesses(inliner.callee, index).declarations
+	inlined := program
+	inlined.Functions = make([]Function, len(program.Functions))
+	for i, function := range program.Functions {
+		if i == index {
+			inlined.Functions[i] = rewriteFunction(function, functionPath(i), func(*Statement, string) {})
+			continue
+		}
+		inliner.function = function.Name
+		inliner.used = make(set)
+		for _, declaration := range collectAccesses(function, i).declarations {
+			inliner.used.add(declaration.Variable)
+		}
+		inlined.Functions[i] = rewriteFunction(function, functionPath(i), inliner.rewrite)
+		if inliner.err != nil {
+			return Program{}, nil, inliner.err
+		}
+	}
+	return inlined, inliner.inlined, nil
+}
+
+// functionInliner holds the state of InlineFunction
+type functionInliner struct {
+	callee    Function
+	variables []Declaration // parameters and local variables of the callee
+	function  string        // function being rewritten
+	used      set           // variable names used by the function being rewritten
+	inlined   []InlinedCall
+	err       error
+}
+
+// rewrite replaces a function_call statement to the callee by the inlined block
+func (in *functionInliner) rewrite(statement *Statement, path string) {
+	isStatement := strings.HasPrefix(path[strings.LastIndex(path, ".")+1:], "statements[")
+	if !isStatement || statement.Type != "function_call" || statement.CalledFunction != in.callee.Name || in.err != nil {
+		return
+	}
+	bound, err := BindArgumentIndices(in.callee, statement.Arguments)
+	if err != nil {
+		in.err = fmt.Errorf("invalid call to %v at %v: %w", in.callee.Name, path, err)
+		return
+	}
+
+	// fresh names of the parameters and the local variables of the callee
+	names := make(map[string]string)
+	for _, variable := range in.variables {
+		if _, renamed := names[variable.Variable]; !renamed {
+			names[variable.Variable] = in.freshName(in.callee.Name + "_" + variable.Variable)
+		}
+	}
+	rename := func(operand *Statement, _ string) {
+		if name, ok := names[operand.Variable]; ok && (operand.Type == "variable" || operand.Type == "variable_declaration") {
+			operand.Variable = name
+		}
+	}
+
+	block := Block{Statements: []Statement{}}
+	parameters := make([]int, len(statement.Arguments)) // parameters[i] is the index of the parameter bound by argument i
+	for i, param := range in.callee.Parameters {
+		block.Statements = append(block.Statements, Statement{Type: "variable_declaration", Variable: names[param.Name]})
+		if bound[i] >= 0 {
+			parameters[bound[i]] = i
+		}
+	}
+	for i, arg := range statement.Arguments {
+		arg.ParameterName = ""
+		block.Statements = append(block.Statements, assignmentStatement(names[in.callee.Parameters[parameters[i]].Name], arg))
+	}
+	for i, param := range in.callee.Parameters {
+		if bound[i] < 0 {
+			value := rewriteStatement(*param.Default, "", rename)
+			block.Statements = append(block.Statements, assignmentStatement(names[param.Name], value))
+		}
+	}
+	body := rewriteBlock(in.callee.Body, "", rename)
+	block.Statements = append(block.Statements, body.Statements...)
+	if len(in.callee.Body.Statements) == 0 {
+		// the call to an empty function is void, not the value of the last assignment of a parameter
+		block.Statements = append(block.Statements, Statement{})
+	}
+
+	in.inlined = append(in.inlined, InlinedCall{Function: in.function, Path: path})
+	*statement = Statement{Type: "block", Block: block}
+}
+
+// freshName returns the given name, followed by the smallest number making it unused, and marks it as used
+func (in *functionInliner) freshName(name string) string {
+	fresh := name
+	for i := 2; ; i++ {
+		if _, used := in.used[fresh]; !used {
+			break
+		}
+		fresh = fmt.Sprintf("%v_%d", name, i)
+	}
+	in.used.add(fresh)
+	return fresh
+}
+
+// assignmentStatement returns the statement assigning the value to the variable
+func assignmentStatement(variable string, value Statement) Statement {
+	return Statement{
+		Type:          "operation",
+		OperationType: "assignment",
+		Operands:      []Statement{{Type: "variable", Variable: variable}, value},
+	}
+}
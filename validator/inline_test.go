@@ -0,0 +1,93 @@
+package validator
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestInlineFunction(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/inline/program.json")
+
+	// the call nested in the argument of display is kept
+	expectedInlined := []InlinedCall{
+		{Function: "main", Path: "functions[1].body.statements[2]"},
+		{Function: "main", Path: "functions[1].body.statements[3].block.statements[0]"},
+		{Function: "main", Path: "functions[1].body.statements[5]"},
+	}
+	result, inlined, err := InlineFunction(program, "add")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(inlined, expectedInlined) {
+		t.Errorf("Unexpected inlined calls. Got %v, want %v", inlined, expectedInlined)
+	}
+	if !ValidateProgramRec(result, false) {
+		t.Errorf("The inlined program is not valid")
+	}
+
+	// main already declares add_x, the parameters are bound in the order of the call, then the default values
+	expectedBlock := []string{
+		"variable_declaration add_x_4",
+		"variable_declaration add_y_3",
+		"assignment add_y_3",
+		"assignment add_x_4",
+		"variable_declaration add_sum_3",
+		"function_call display",
+		"assignment add_sum_3",
+	}
+	block := []string{}
+	for _, statement := range result.Functions[1].Body.Statements[5].Block.Statements {
+		switch statement.Type {
+		case "variable_declaration":
+			block = append(block, statement.Type+" "+statement.Variable)
+		case "function_call":
+			block = append(block, statement.Type+" "+statement.CalledFunction)
+		case "operation":
+			block = append(block, statement.OperationType+" "+statement.Operands[0].Variable)
+		}
+	}
+	if !reflect.DeepEqual(block, expectedBlock) {
+		t.Errorf("Unexpected inlined block. Got %v, want %v", block, expectedBlock)
+	}
+
+	// the inlined program computes the same result
+	for _, p := range []Program{program, result} {
+		value, err := NewInterpreter(p, nil).Run("main", []Value{IntValue(4)})
+		if err != nil || value != IntValue(7) {
+			t.Errorf("Unexpected result. Got %v (error: %v), want %v", value, err, IntValue(7))
+		}
+	}
+}
+
+func TestInlineFunction_EmptyBody(t *testing.T) {
+	// the value of main is the value of the call to f, which is void
+	program := Program{Functions: []Function{
+		{Name: "main", Parameters: []Parameter{}, Body: Block{Statements: []Statement{
+			{Type: "function_call", CalledFunction: "f", Arguments: []Statement{{Type: "numerical", Value: "3"}}},
+		}}},
+		{Name: "f", Parameters: []Parameter{{Name: "a"}}, Body: Block{Statements: []Statement{}}},
+	}}
+
+	result, _, err := InlineFunction(program, "f")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	block := result.Functions[0].Body.Statements[0].Block.Statements
+	if last := block[len(block)-1]; !reflect.DeepEqual(last, Statement{}) {
+		t.Errorf("Unexpected last statement of the inlined block. Got %v, want an empty statement", last)
+	}
+	for _, p := range []Program{program, result} {
+		value, err := NewInterpreter(p, nil).Run("main", nil)
+		if err != nil || value != Void {
+			t.Errorf("Unexpected result. Got %v (error: %v), want %v", value, err, Void)
+		}
+	}
+}
+
+func TestInlineFunction_RecursiveFunction(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/run/infinite_recursion.json")
+	if _, _, err := InlineFunction(program, "countdown"); !errors.Is(err, ErrRecursiveFunction) {
+		t.Errorf("Unexpected error. Got %v, want %v", err, ErrRecursiveFunction)
+	}
+}
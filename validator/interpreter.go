@@ -0,0 +1,485 @@
+package validator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// -----------------------------------------
+// Runtime values
+// -----------------------------------------
+
+// Value is the value of an operand at runtime
+type Value struct {
+	Type  Type // TypeUnknown for Void, the value of statements without a result
+	Int   int64
+	Float float64
+	Bool  bool
+	Text  string
+}
+
+// Void is the value of statements without a result, such as variable declarations
+var Void = Value{}
+
+func IntValue(value int64) Value     { return Value{Type: TypeInt, Int: value} }
+func FloatValue(value float64) Value { return Value{Type: TypeFloat, Float: value} }
+func BoolValue(value bool) Value     { return Value{Type: TypeBool, Bool: value} }
+func StringValue(value string) Value { return Value{Type: TypeString, Text: value} }
+
+// IsNumeric checks if the value is an int or a float
+func (v Value) IsNumeric() bool {
+	return v.Type == TypeInt || v.Type == TypeFloat
+}
+
+// AsFloat returns a numeric value as a float
+func (v Value) AsFloat() float64 {
+	if v.Type == TypeInt {
+		return float64(v.Int)
+	}
+	return v.Float
+}
+
+// String returns the value as written in a program, strings are quoted
+func (v Value) String() string {
+	switch v.Type {
+	case TypeInt:
+		return strconv.FormatInt(v.Int, 10)
+	case TypeFloat:
+		text := strconv.FormatFloat(v.Float, 'g', -1, 64)
+		// keep floats distinguishable from integers
+		if !strings.ContainsAny(text, ".eIN") {
+			text += ".0"
+		}
+		return text
+	case TypeBool:
+		return strconv.FormatBool(v.Bool)
+	case TypeString:
+		return strconv.Quote(v.Text)
+	}
+	return "void"
+}
+
+// MarshalJSON writes numbers, booleans and strings as JSON values, and Void as null.
+// Non finite floats, which JSON can't represent, are written as strings.
+func (v Value) MarshalJSON() ([]byte, error) {
+	switch v.Type {
+	case TypeInt:
+		return json.Marshal(v.Int)
+	case TypeFloat:
+		if math.IsInf(v.Float, 0) || math.IsNaN(v.Float) {
+			return json.Marshal(v.String())
+		}
+		return json.Marshal(v.Float)
+	case TypeBool:
+		return json.Marshal(v.Bool)
+	case TypeString:
+		return json.Marshal(v.Text)
+	}
+	return []byte("null"), nil
+}
+
+// LiteralValue returns the value of a numerical, string or boolean operand.
+// Numerical values are integers if they can be parsed as an int64, floats otherwise.
+func LiteralValue(operand Statement) (Value, error) {
+	switch operand.Type {
+	case "numerical":
+		if value, err := strconv.ParseInt(operand.Value, 10, 64); err == nil {
+			return IntValue(value), nil
+		}
+		value, err := strconv.ParseFloat(operand.Value, 64)
+		if err != nil && !errors.Is(err, strconv.ErrRange) {
+			return Void, fmt.Errorf("invalid numerical value: %v", operand.Value)
+		}
+		return FloatValue(value), nil
+	case "string":
+		value, err := UnescapeString(operand.Value)
+		if err != nil {
+			return Void, err
+		}
+		return StringValue(value), nil
+	case "boolean":
+		value, err := ParseBoolean(operand.Value)
+		if err != nil {
+			return Void, err
+		}
+		return BoolValue(value), nil
+	}
+	return Void, fmt.Errorf("%v operand is not a literal", operand.Type)
+}
+
+// ParseValue converts a command line argument to a value: an int, a float, a bool, or otherwise a string
+func ParseValue(text string) Value {
+	if value, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return IntValue(value)
+	}
+	if value, err := strconv.ParseFloat(text, 64); err == nil && !math.IsInf(value, 0) && !math.IsNaN(value) {
+		return FloatValue(value)
+	}
+	if value, err := ParseBoolean(text); err == nil {
+		return BoolValue(value)
+	}
+	return StringValue(text)
+}
+
+// -----------------------------------------
+// Interpreter
+// -----------------------------------------
+/*
+	Semantics:
+		- a function call evaluates to the value of the last statement executed in the function body, a block evaluates
+		to the value of its last statement, and an assignment evaluates to the assigned value.
+		- arguments are evaluated in the caller from left to right, omitted arguments are replaced by the parameter's
+		default value, which is evaluated in the called function after binding the preceding parameters.
+		- variables are visible from their declaration until the end of the surrounding block.
+		- calls to functions which are not declared in the program are resolved using the host built-in functions.
+*/
+
+// BuiltinFunc is a host function callable from programs, it receives the values of the (positional) arguments
+type BuiltinFunc func(arguments []Value) (Value, error)
+
+// DefaultBuiltins returns the built-in functions available to programs:
+//   - print: writes its arguments separated by spaces and followed by a new line to out. Strings are written unquoted.
+func DefaultBuiltins(out io.Writer) map[string]BuiltinFunc {
+	return map[string]BuiltinFunc{
+		"print": func(arguments []Value) (Value, error) {
+			texts := make([]string, len(arguments))
+			for i, arg := range arguments {
+				if arg.Type == TypeString {
+					texts[i] = arg.Text
+				} else {
+					texts[i] = arg.String()
+				}
+			}
+			_, err := fmt.Fprintln(out, strings.Join(texts, " "))
+			return Void, err
+		},
+	}
+}
+
+// IsBuiltin returns true if the function is one of DefaultBuiltins, which programs can call without declaring it
+func IsBuiltin(functionName string) bool {
+	_, builtin := defaultBuiltins[functionName]
+	return builtin
+}
+
+// defaultBuiltins holds the built-in functions of DefaultBuiltins, only their names are used
+var defaultBuiltins = DefaultBuiltins(io.Discard)
+
+// Errors reported when a program exceeds the budget of its interpreter
+var (
+	ErrStepBudgetExceeded = errors.New("step budget exceeded")
+	ErrCallDepthExceeded  = errors.New("call depth limit exceeded")
+)
+
+// DefaultMaxCallDepth is the call depth limit of new interpreters. It stops runaway recursion,
+// which is possible since the call graph may contain cycles, before it exhausts the Go stack.
+const DefaultMaxCallDepth = 10000
+
+// TraceEvent describes a step of the execution of a program
+type TraceEvent struct {
+	// Kind is one of:
+	// - "call": a function is called, before binding its parameters
+	// - "statement": a statement of a block is about to be executed
+	// - "assignment": a variable was assigned, Variable and Value hold the assigned variable and value
+	// - "return": a function call returned Value
+	Kind      string `json:"kind"`
+	Step      int    `json:"step"`                // number of statements executed so far, including this one
+	Depth     int    `json:"depth"`               // call stack depth, 1 for the entry function
+	Function  string `json:"function"`            // function executing the step
+	Path      string `json:"path"`                // JSON path of the function, statement or assignment
+	Statement string `json:"statement,omitempty"` // type of the executed statement
+	Variable  string `json:"variable,omitempty"`
+	Value     *Value `json:"value,omitempty"`
+}
+
+// JSONLinesTracer writes trace events as JSON lines, it is used as an Interpreter's Trace hook
+type JSONLinesTracer struct {
+	encoder *json.Encoder
+	// Err holds the first error writing the events, later events are dropped
+	Err error
+}
+
+// NewJSONLinesTracer creates a tracer writing to out
+func NewJSONLinesTracer(out io.Writer) *JSONLinesTracer {
+	return &JSONLinesTracer{encoder: json.NewEncoder(out)}
+}
+
+// Trace writes an event as one line of JSON
+func (tracer *JSONLinesTracer) Trace(event TraceEvent) {
+	if tracer.Err == nil {
+		tracer.Err = tracer.encoder.Encode(event)
+	}
+}
+
+// RuntimeError reports an error raised while executing a statement
+type RuntimeError struct {
+	Function string // name of the function executing the statement
+	Path     string // JSON path of the statement
+	Err      error
+}
+
+func (e *RuntimeError) Error() string {
+	return fmt.Sprintf("%v (function: %v, at %v)", e.Err, e.Function, e.Path)
+}
+
+func (e *RuntimeError) Unwrap() error {
+	return e.Err
+}
+
+// Interpreter executes the functions of a program
+type Interpreter struct {
+	Program  Program
+	Builtins map[string]BuiltinFunc
+	// Trace is called for each step of the execution, see TraceEvent. It is optional.
+	Trace func(event TraceEvent)
+	// MaxSteps limits the number of executed statements of a run, 0 for no limit
+	MaxSteps int
+	// MaxCallDepth limits the depth of nested function calls, 0 for no limit
+	MaxCallDepth int
+
+	// functions maps the name of each function to its index in the program
+	functions map[string]int
+	// steps counts the statements executed by the current run
+	steps int
+}
+
+// NewInterpreter creates an interpreter for the program using the given host built-in functions.
+// The call depth is limited to DefaultMaxCallDepth and the number of steps isn't limited.
+func NewInterpreter(program Program, builtins map[string]BuiltinFunc) *Interpreter {
+	functions := make(map[string]int)
+	for i, function := range program.Functions {
+		functions[function.Name] = i
+	}
+	return &Interpreter{Program: program, Builtins: builtins, MaxCallDepth: DefaultMaxCallDepth, functions: functions}
+}
+
+// trace sends an event to the Trace hook, if any
+func (in *Interpreter) trace(callFrame *frame, event TraceEvent) {
+	if in.Trace == nil {
+		return
+	}
+	event.Step = in.steps
+	event.Depth = callFrame.depth
+	event.Function = callFrame.function
+	in.Trace(event)
+}
+
+// binding holds the value of a variable
+type binding struct {
+	assigned bool
+	value    Value
+}
+
+// frame holds the variables of a function call, with one scope per block
+type frame struct {
+	function string
+	depth    int // call stack depth, 1 for the entry function
+	scopes   []map[string]*binding
+}
+
+func (f *frame) pushScope() {
+	f.scopes = append(f.scopes, make(map[string]*binding))
+}
+
+func (f *frame) popScope() {
+	f.scopes = f.scopes[:len(f.scopes)-1]
+}
+
+// lookup returns the innermost variable with the given name, or nil if it isn't declared
+func (f *frame) lookup(name string) *binding {
+	for i := len(f.scopes) - 1; i >= 0; i-- {
+		if variable, declared := f.scopes[i][name]; declared {
+			return variable
+		}
+	}
+	return nil
+}
+
+// Run calls the entry function with positional arguments and returns the value of the call
+func (in *Interpreter) Run(entry string, arguments []Value) (Value, error) {
+	index, declared := in.functions[entry]
+	if !declared {
+		return Void, fmt.Errorf("entry function: %v is not declared", entry)
+	}
+	function := in.Program.Functions[index]
+	if len(arguments) > len(function.Parameters) {
+		return Void, fmt.Errorf("too many arguments for entry function: %v, expects at most %v but received %v", entry, len(function.Parameters), len(arguments))
+	}
+	bound := make([]int, len(function.Parameters))
+	for i, param := range function.Parameters {
+		if i < len(arguments) {
+			bound[i] = i
+			continue
+		}
+		if param.Default == nil {
+			return Void, fmt.Errorf("missing argument for parameter: %v of entry function: %v", param.Name, entry)
+		}
+		bound[i] = -1
+	}
+	in.steps = 0
+	return in.call(index, arguments, bound, 1)
+}
+
+// call executes the function at the given index. bound holds the index of the argument bound to each parameter,
+// or -1 to use the parameter's default value.
+func (in *Interpreter) call(index int, arguments []Value, bound []int, depth int) (Value, error) {
+	function := in.Program.Functions[index]
+	path := functionPath(index)
+	callFrame := &frame{function: function.Name, depth: depth}
+	in.trace(callFrame, TraceEvent{Kind: "call", Path: path})
+	callFrame.pushScope()
+	for i, param := range function.Parameters {
+		value := Void
+		if bound[i] >= 0 {
+			value = arguments[bound[i]]
+		} else {
+			var err error
+			value, err = in.evaluate(*param.Default, callFrame, elementPath(path, "parameters", i)+".default")
+			if err != nil {
+				return Void, err
+			}
+		}
+		callFrame.scopes[0][param.Name] = &binding{assigned: true, value: value}
+	}
+	result, err := in.executeBlock(function.Body, callFrame, path+".body")
+	if err != nil {
+		return Void, err
+	}
+	in.trace(callFrame, TraceEvent{Kind: "return", Path: path, Value: &result})
+	return result, nil
+}
+
+func (in *Interpreter) executeBlock(block Block, callFrame *frame, path string) (Value, error) {
+	callFrame.pushScope()
+	defer callFrame.popScope()
+	result := Void
+	for i, statement := range block.Statements {
+		statementPath := elementPath(path, "statements", i)
+		in.steps++
+		if in.MaxSteps > 0 && in.steps > in.MaxSteps {
+			return Void, in.fail(callFrame, statementPath, fmt.Errorf("%w: more than %v statements executed", ErrStepBudgetExceeded, in.MaxSteps))
+		}
+		in.trace(callFrame, TraceEvent{Kind: "statement", Path: statementPath, Statement: statement.Type})
+		value, err := in.evaluate(statement, callFrame, statementPath)
+		if err != nil {
+			return Void, err
+		}
+		result = value
+	}
+	return result, nil
+}
+
+// evaluate executes a statement or evaluates an operand, both are evaluated the same way
+func (in *Interpreter) evaluate(statement Statement, callFrame *frame, path string) (Value, error) {
+	switch statement.Type {
+	case "":
+		// empty statements are ignored
+		return Void, nil
+	case "block":
+		return in.executeBlock(statement.Block, callFrame, path+".block")
+	case "variable_declaration":
+		callFrame.scopes[len(callFrame.scopes)-1][statement.Variable] = &binding{}
+		return Void, nil
+	case "numerical", "string", "boolean":
+		value, err := LiteralValue(statement)
+		if err != nil {
+			return Void, in.fail(callFrame, path, err)
+		}
+		return value, nil
+	case "variable":
+		variable := callFrame.lookup(statement.Variable)
+		if variable == nil {
+			return Void, in.fail(callFrame, path, fmt.Errorf("variable: %v is not declared", statement.Variable))
+		}
+		if !variable.assigned {
+			return Void, in.fail(callFrame, path, fmt.Errorf("variable: %v is used without assignment", statement.Variable))
+		}
+		return variable.value, nil
+	case "operation":
+		return in.evaluateOperation(statement, callFrame, path)
+	case "function_call":
+		return in.evaluateCall(statement, callFrame, path)
+	}
+	return Void, in.fail(callFrame, path, fmt.Errorf("unknown statement type: %v", statement.Type))
+}
+
+func (in *Interpreter) evaluateOperation(operation Statement, callFrame *frame, path string) (Value, error) {
+	if operation.OperationType == "assignment" {
+		if len(operation.Operands) != 2 || operation.Operands[0].Type != "variable" {
+			return Void, in.fail(callFrame, path, fmt.Errorf("assignment expects a variable and a value"))
+		}
+		variable := callFrame.lookup(operation.Operands[0].Variable)
+		if variable == nil {
+			return Void, in.fail(callFrame, elementPath(path, "operands", 0), fmt.Errorf("variable: %v is not declared", operation.Operands[0].Variable))
+		}
+		value, err := in.evaluate(operation.Operands[1], callFrame, elementPath(path, "operands", 1))
+		if err != nil {
+			return Void, err
+		}
+		variable.assigned = true
+		variable.value = value
+		in.trace(callFrame, TraceEvent{Kind: "assignment", Path: path, Variable: operation.Operands[0].Variable, Value: &value})
+		return value, nil
+	}
+
+	operands := make([]Value, len(operation.Operands))
+	for i, operand := range operation.Operands {
+		value, err := in.evaluate(operand, callFrame, elementPath(path, "operands", i))
+		if err != nil {
+			return Void, err
+		}
+		operands[i] = value
+	}
+	value, err := EvaluateOperation(operation.OperationType, operands)
+	if err != nil {
+		return Void, in.fail(callFrame, path, err)
+	}
+	return value, nil
+}
+
+func (in *Interpreter) evaluateCall(call Statement, callFrame *frame, path string) (Value, error) {
+	arguments := make([]Value, len(call.Arguments))
+	for i, arg := range call.Arguments {
+		value, err := in.evaluate(arg, callFrame, elementPath(path, "arguments", i))
+		if err != nil {
+			return Void, err
+		}
+		arguments[i] = value
+	}
+
+	// functions declared in the program take precedence over the built-in functions
+	if index, declared := in.functions[call.CalledFunction]; declared {
+		bound, err := BindArgumentIndices(in.Program.Functions[index], call.Arguments)
+		if err != nil {
+			return Void, in.fail(callFrame, path, fmt.Errorf("invalid call to function: %v: %w", call.CalledFunction, err))
+		}
+		if in.MaxCallDepth > 0 && callFrame.depth >= in.MaxCallDepth {
+			return Void, in.fail(callFrame, path, fmt.Errorf("%w: more than %v nested calls calling function: %v", ErrCallDepthExceeded, in.MaxCallDepth, call.CalledFunction))
+		}
+		return in.call(index, arguments, bound, callFrame.depth+1)
+	}
+	builtin, isBuiltin := in.Builtins[call.CalledFunction]
+	if !isBuiltin {
+		return Void, in.fail(callFrame, path, fmt.Errorf("function: %v is not declared", call.CalledFunction))
+	}
+	for _, arg := range call.Arguments {
+		if arg.ParameterName != "" {
+			return Void, in.fail(callFrame, path, fmt.Errorf("built-in function: %v doesn't accept named arguments", call.CalledFunction))
+		}
+	}
+	value, err := builtin(arguments)
+	if err != nil {
+		return Void, in.fail(callFrame, path, fmt.Errorf("built-in function: %v failed: %w", call.CalledFunction, err))
+	}
+	return value, nil
+}
+
+// fail wraps an error raised by the statement at the given path into a RuntimeError
+func (in *Interpreter) fail(callFrame *frame, path string, err error) error {
+	return &RuntimeError{Function: callFrame.function, Path: path, Err: err}
+}
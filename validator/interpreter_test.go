@@ -0,0 +1,196 @@
+package validator
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// runTestCase a helper function to run a test case and compare its output and result
+func runTestCase(t *testing.T, filepath string, arguments []Value, expectedOutput string, expectedResult Value) {
+	// Define the input program
+	program := ReadTestCaseFromJSON(filepath)
+
+	// Run the program, capturing its output
+	var output bytes.Buffer
+	interpreter := NewInterpreter(program, DefaultBuiltins(&output))
+	result, err := interpreter.Run("main", arguments)
+
+	// Compare the result with the expected output
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if output.String() != expectedOutput {
+		t.Errorf("Unexpected output. Got %q, want %q", output.String(), expectedOutput)
+	}
+	if result != expectedResult {
+		t.Errorf("Unexpected result. Got %v, want %v", result, expectedResult)
+	}
+}
+
+func TestRun_PrintSum(t *testing.T) {
+	expectedOutput := "start\nsum: 12\n24 true\n"
+	runTestCase(t, "../data/run/print_sum.json", nil, expectedOutput, FloatValue(1))
+}
+
+func TestRun_DivisionByParameter(t *testing.T) {
+	runTestCase(t, "../data/run/division_by_parameter.json", []Value{IntValue(4)}, "2\n", Void)
+	runTestCase(t, "../data/run/division_by_parameter.json", []Value{FloatValue(0.5)}, "20.0\n", Void)
+}
+
+func TestRun_DivisionByZero(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/run/division_by_parameter.json")
+	interpreter := NewInterpreter(program, DefaultBuiltins(&bytes.Buffer{}))
+	_, err := interpreter.Run("main", []Value{IntValue(0)})
+
+	var runtimeError *RuntimeError
+	if !errors.As(err, &runtimeError) || !errors.Is(err, ErrDivisionByZero) {
+		t.Fatalf("Unexpected error. Got %v, want a division by zero", err)
+	}
+	expectedPath := "functions[0].body.statements[1].operands[1]"
+	if runtimeError.Function != "main" || runtimeError.Path != expectedPath {
+		t.Errorf("Unexpected location. Got %v at %v, want main at %v", runtimeError.Function, runtimeError.Path, expectedPath)
+	}
+}
+
+func TestRun_CustomBuiltins(t *testing.T) {
+	// display is declared in the program, so it takes precedence over the built-in function
+	program := ReadTestCaseFromJSON("../data/valid/function_call_operand.json")
+	calls := 0
+	builtins := map[string]BuiltinFunc{
+		"display": func(arguments []Value) (Value, error) {
+			calls++
+			return Void, nil
+		},
+	}
+	if _, err := NewInterpreter(program, builtins).Run("main", nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("Unexpected calls to the built-in function. Got %v, want 0", calls)
+	}
+
+	// print is neither declared nor a built-in function
+	program = ReadTestCaseFromJSON("../data/run/print_sum.json")
+	if _, err := NewInterpreter(program, builtins).Run("main", nil); err == nil {
+		t.Errorf("Expected an error calling an undeclared function")
+	}
+}
+
+func TestEvaluateOperation(t *testing.T) {
+	testCases := []struct {
+		operationType string
+		operands      []Value
+		expected      Value
+		err           error
+	}{
+		{"addition", []Value{IntValue(1), IntValue(2), IntValue(3)}, IntValue(6), nil},
+		{"addition", []Value{IntValue(1), FloatValue(0.5)}, FloatValue(1.5), nil},
+		{"addition", []Value{IntValue(math.MaxInt64), IntValue(1)}, Void, ErrIntegerOverflow},
+		{"subtraction", []Value{IntValue(math.MinInt64), IntValue(1)}, Void, ErrIntegerOverflow},
+		{"multiplication", []Value{IntValue(math.MaxInt64 / 2), IntValue(3)}, Void, ErrIntegerOverflow},
+		{"multiplication", []Value{FloatValue(1e308), IntValue(10)}, Void, ErrFloatOverflow},
+		{"division", []Value{IntValue(7), IntValue(2)}, IntValue(3), nil},
+		{"division", []Value{FloatValue(7), IntValue(2)}, FloatValue(3.5), nil},
+		{"division", []Value{IntValue(7), IntValue(0)}, Void, ErrDivisionByZero},
+		{"modulo", []Value{IntValue(7), IntValue(3)}, IntValue(1), nil},
+		{"modulo", []Value{FloatValue(7), IntValue(3)}, Void, ErrOperandType},
+		{"negation", []Value{IntValue(math.MinInt64)}, Void, ErrIntegerOverflow},
+		{"equal", []Value{IntValue(2), FloatValue(2)}, BoolValue(true), nil},
+		{"not_equal", []Value{StringValue("a"), StringValue("b")}, BoolValue(true), nil},
+		{"equal", []Value{StringValue("1"), IntValue(1)}, Void, ErrOperandType},
+		{"less_than", []Value{IntValue(math.MaxInt64 - 1), IntValue(math.MaxInt64)}, BoolValue(true), nil},
+		{"greater_equal", []Value{FloatValue(2.5), IntValue(3)}, BoolValue(false), nil},
+		{"and", []Value{BoolValue(true), BoolValue(false)}, BoolValue(false), nil},
+		{"or", []Value{BoolValue(false), BoolValue(true)}, BoolValue(true), nil},
+		{"not", []Value{IntValue(1)}, Void, ErrOperandType},
+		{"concatenation", []Value{StringValue("a"), StringValue("b")}, StringValue("ab"), nil},
+	}
+	for _, testCase := range testCases {
+		result, err := EvaluateOperation(testCase.operationType, testCase.operands)
+		if result != testCase.expected || !errors.Is(err, testCase.err) || (testCase.err == nil && err != nil) {
+			t.Errorf("EvaluateOperation(%v, %v) = %v, %v, want %v, %v", testCase.operationType, testCase.operands, result, err, testCase.expected, testCase.err)
+		}
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	testCases := map[string]Value{
+		"10":    IntValue(10),
+		"2.5":   FloatValue(2.5),
+		"true":  BoolValue(true),
+		"done":  StringValue("done"),
+		"NaN":   StringValue("NaN"),
+		"1e400": StringValue("1e400"),
+	}
+	for text, expected := range testCases {
+		if result := ParseValue(text); result != expected {
+			t.Errorf("ParseValue(%q) = %v, want %v", text, result, expected)
+		}
+	}
+}
+
+func TestRun_CallDepthExceeded(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/run/infinite_recursion.json")
+	interpreter := NewInterpreter(program, DefaultBuiltins(&bytes.Buffer{}))
+	_, err := interpreter.Run("main", nil)
+	if !errors.Is(err, ErrCallDepthExceeded) {
+		t.Errorf("Unexpected error. Got %v, want %v", err, ErrCallDepthExceeded)
+	}
+}
+
+func TestRun_StepBudgetExceeded(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/run/infinite_recursion.json")
+	var output bytes.Buffer
+	interpreter := NewInterpreter(program, DefaultBuiltins(&output))
+	interpreter.MaxSteps = 4
+	_, err := interpreter.Run("main", nil)
+	if !errors.Is(err, ErrStepBudgetExceeded) {
+		t.Errorf("Unexpected error. Got %v, want %v", err, ErrStepBudgetExceeded)
+	}
+	// main's call, then print and the recursive call for 10 and 9
+	if output.String() != "10\n9\n" {
+		t.Errorf("Unexpected output. Got %q, want %q", output.String(), "10\n9\n")
+	}
+}
+
+func TestRun_Trace(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/run/division_by_parameter.json")
+	events := []TraceEvent{}
+	interpreter := NewInterpreter(program, DefaultBuiltins(&bytes.Buffer{}))
+	interpreter.Trace = func(event TraceEvent) {
+		events = append(events, event)
+	}
+	if _, err := interpreter.Run("main", []Value{IntValue(4)}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	value := IntValue(2)
+	expectedEvents := []TraceEvent{
+		{Kind: "call", Step: 0, Depth: 1, Function: "main", Path: "functions[0]"},
+		{Kind: "statement", Step: 1, Depth: 1, Function: "main", Path: "functions[0].body.statements[0]", Statement: "variable_declaration"},
+		{Kind: "statement", Step: 2, Depth: 1, Function: "main", Path: "functions[0].body.statements[1]", Statement: "operation"},
+		{Kind: "assignment", Step: 2, Depth: 1, Function: "main", Path: "functions[0].body.statements[1]", Variable: "x", Value: &value},
+		{Kind: "statement", Step: 3, Depth: 1, Function: "main", Path: "functions[0].body.statements[2]", Statement: "function_call"},
+		{Kind: "return", Step: 3, Depth: 1, Function: "main", Path: "functions[0]", Value: &Void},
+	}
+	if !reflect.DeepEqual(events, expectedEvents) {
+		t.Errorf("Unexpected trace. Got %v, want %v", events, expectedEvents)
+	}
+}
+
+func TestJSONLinesTracer(t *testing.T) {
+	var output bytes.Buffer
+	tracer := NewJSONLinesTracer(&output)
+	value := StringValue("done")
+	tracer.Trace(TraceEvent{Kind: "assignment", Step: 2, Depth: 1, Function: "main", Path: "functions[0].body.statements[1]", Variable: "x", Value: &value})
+	tracer.Trace(TraceEvent{Kind: "return", Step: 2, Depth: 1, Function: "main", Path: "functions[0]", Value: &Void})
+
+	expectedOutput := `{"kind":"assignment","step":2,"depth":1,"function":"main","path":"functions[0].body.statements[1]","variable":"x","value":"done"}` + "\n" +
+		`{"kind":"return","step":2,"depth":1,"function":"main","path":"functions[0]","value":null}` + "\n"
+	if tracer.Err != nil || output.String() != expectedOutput {
+		t.Errorf("Unexpected output. Got %q, %v, want %q", output.String(), tracer.Err, expectedOutput)
+	}
+}
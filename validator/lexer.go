@@ -0,0 +1,258 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// -----------------------------------------
+// Lexer of the textual syntax
+// -----------------------------------------
+/*
+	The textual syntax, in .vl files, is the pseudo-code written by PrintProgram. The lexer splits it into:
+		- identifiers and the keywords func, var, true, false, module, import, export, public and private.
+		- numbers, starting with a digit, e.g. 10, 2.5, 1e-3 or 0x1p4.
+		- strings between double quotes, which may hold the escape sequences of UnescapeString.
+		- the symbols of the operation registry, and the punctuation ( ) { } , ; : # .
+	Spaces, line comments starting with // and block comments starting with /* and ending with a star followed by a
+	slash are skipped.
+*/
+
+// tokenKind is the kind of a token
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdentifier
+	tokenKeyword
+	tokenNumber
+	tokenString
+	tokenSymbol // operator or punctuation
+)
+
+// token is a lexical unit of the textual syntax
+type token struct {
+	kind   tokenKind
+	text   string // the token as written, the content between the double quotes for a string
+	line   int    // counting from 1
+	column int    // counting from 1, in bytes
+}
+
+// describe returns the token as written in error messages
+func (t token) describe() string {
+	switch t.kind {
+	case tokenEOF:
+		return "the end of the file"
+	case tokenString:
+		return fmt.Sprintf("string \"%v\"", t.text)
+	}
+	return fmt.Sprintf("'%v'", t.text)
+}
+
+// SyntaxError reports an error in a program written in the textual syntax
+type SyntaxError struct {
+	Line    int // counting from 1
+	Column  int // counting from 1, in bytes
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%d:%d: %v", e.Line, e.Column, e.Message)
+}
+
+// keywords of the textual syntax, they can't be used as identifiers
+var keywords = map[string]bool{
+	"func": true, "var": true, "true": true, "false": true,
+	"module": true, "import": true, "export": true, "public": true, "private": true,
+}
+
+// punctuation of the textual syntax, the operators are the symbols of the operation registry
+var punctuation = []string{"(", ")", "{", "}", ",", ";", ":", "#", "."}
+
+// lexer holds the state of tokenize
+type lexer struct {
+	source   string
+	offset   int
+	line     int
+	column   int
+	symbols  []string // operators and punctuation, longest first
+	tokens   []token
+	comments int // number of skipped comments
+}
+
+// tokenize splits the source into tokens, ending with a tokenEOF token
+func tokenize(source string) ([]token, error) {
+	l, err := scan(source)
+	if err != nil {
+		return nil, err
+	}
+	return l.tokens, nil
+}
+
+// hasComments returns true if the source holds comments, which the tokens don't keep
+func hasComments(source string) (bool, error) {
+	l, err := scan(source)
+	if err != nil {
+		return false, err
+	}
+	return l.comments > 0, nil
+}
+
+// scan runs the lexer over the whole source
+func scan(source string) (*lexer, error) {
+	l := &lexer{source: source, line: 1, column: 1, symbols: append([]string{}, punctuation...)}
+	for _, spec := range Operations {
+		if spec.Symbol != "" {
+			l.symbols = append(l.symbols, spec.Symbol)
+		}
+	}
+	// the longest symbols are tried first, e.g. == before =
+	sort.Slice(l.symbols, func(i, j int) bool { return len(l.symbols[i]) > len(l.symbols[j]) })
+
+	for {
+		if err := l.skipSpaces(); err != nil {
+			return nil, err
+		}
+		if l.offset == len(l.source) {
+			l.tokens = append(l.tokens, token{kind: tokenEOF, line: l.line, column: l.column})
+			return l, nil
+		}
+		if err := l.next(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (l *lexer) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Line: l.line, Column: l.column, Message: fmt.Sprintf(format, args...)}
+}
+
+// advance moves forward by n bytes, keeping track of the line and the column
+func (l *lexer) advance(n int) {
+	for _, c := range []byte(l.source[l.offset : l.offset+n]) {
+		if c == '\n' {
+			l.line++
+			l.column = 1
+		} else {
+			l.column++
+		}
+	}
+	l.offset += n
+}
+
+// skipSpaces skips the spaces and the comments
+func (l *lexer) skipSpaces() error {
+	for l.offset < len(l.source) {
+		rest := l.source[l.offset:]
+		switch {
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\n' || rest[0] == '\r':
+			l.advance(1)
+		case strings.HasPrefix(rest, "//"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			l.advance(end)
+			l.comments++
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				return l.errorf("unterminated comment")
+			}
+			l.advance(end + 4)
+			l.comments++
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// next reads the token at the current offset
+func (l *lexer) next() error {
+	rest := l.source[l.offset:]
+	start := token{line: l.line, column: l.column}
+	c := rest[0]
+	switch {
+	case isLetter(c):
+		end := 1
+		for end < len(rest) && (isLetter(rest[end]) || isDigit(rest[end])) {
+			end++
+		}
+		start.kind, start.text = tokenIdentifier, rest[:end]
+		if keywords[start.text] {
+			start.kind = tokenKeyword
+		}
+		l.advance(end)
+	case isDigit(c):
+		start.kind, start.text = tokenNumber, rest[:numberLength(rest)]
+		l.advance(len(start.text))
+	case c == '"':
+		end := 1
+		for ; end < len(rest) && rest[end] != '"'; end++ {
+			if rest[end] == '\n' {
+				break
+			}
+			if rest[end] == '\\' {
+				end++
+			}
+		}
+		if end >= len(rest) || rest[end] != '"' {
+			return l.errorf("unterminated string")
+		}
+		start.kind, start.text = tokenString, rest[1:end]
+		l.advance(end + 1)
+	default:
+		for _, symbol := range l.symbols {
+			if strings.HasPrefix(rest, symbol) {
+				start.kind, start.text = tokenSymbol, symbol
+				l.advance(len(symbol))
+				l.tokens = append(l.tokens, start)
+				return nil
+			}
+		}
+		return l.errorf("unexpected character %q", c)
+	}
+	l.tokens = append(l.tokens, start)
+	return nil
+}
+
+// numberLength returns the length of the number at the start of the text: letters, digits, dots and underscores,
+// along with the sign of the exponent of decimal (e) and hexadecimal (p) numbers
+func numberLength(text string) int {
+	hexadecimal := len(text) > 1 && (text[1] == 'x' || text[1] == 'X')
+	end := 1
+	for end < len(text) {
+		c := text[end]
+		previous := text[end-1] | 0x20 // lower case
+		isSign := (c == '+' || c == '-') && ((previous == 'e' && !hexadecimal) || (previous == 'p' && hexadecimal))
+		if !isLetter(c) && !isDigit(c) && c != '.' && !isSign {
+			break
+		}
+		end++
+	}
+	return end
+}
+
+// IsIdentifier returns true if the name can be written as an identifier of the textual syntax: a letter or an
+// underscore, followed by letters, underscores and digits, which isn't a keyword
+func IsIdentifier(name string) bool {
+	if name == "" || !isLetter(name[0]) || keywords[name] {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		if !isLetter(name[i]) && !isDigit(name[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isLetter(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
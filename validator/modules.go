@@ -0,0 +1,301 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// -----------------------------------------
+// Modules
+// -----------------------------------------
+/*
+	A program named by its "module" field is a module, whose functions can be called from the programs importing it:
+		- a program lists the modules it uses in its "imports" field, and calls their functions by their qualified
+		name, e.g. geometry.area(...) calls the function area of the module geometry.
+		- a module exports the functions listed in its "exports" field, or all its functions if the field is omitted.
+		The other functions are private, they can only be called from the module itself.
+		- a function may declare its visibility, "public" or "private", which takes precedence over the exports of
+		the module. A private function can't be listed in the exports.
+		- the programs are loaded together by LoadModules, and each of them is validated by ValidateModule.
+		- the imported modules must be loaded, and a module can't import itself, directly or not.
+	The analyses other than the validation, e.g. FindFunctionCalls, consider each program on its own, so the qualified
+	names are handled as the names of functions declared elsewhere.
+*/
+
+var (
+	// ErrDuplicateModule is returned when several programs declare the same module
+	ErrDuplicateModule = errors.New("module is declared more than once")
+)
+
+// Visibilities of a function
+const (
+	PublicVisibility  = "public"
+	PrivateVisibility = "private"
+)
+
+// Modules holds the modules loaded together, keyed by their name
+type Modules map[string]Program
+
+// LoadModules returns the modules declared by the programs. The programs without a module name may import modules,
+// but they can't be imported.
+func LoadModules(programs []Program) (Modules, error) {
+	modules := Modules{}
+	for _, program := range programs {
+		if program.Module == "" {
+			continue
+		}
+		if _, declared := modules[program.Module]; declared {
+			return nil, fmt.Errorf("%w: %v", ErrDuplicateModule, program.Module)
+		}
+		modules[program.Module] = program
+	}
+	return modules, nil
+}
+
+// IsExported returns true if the function can be called from the programs importing the module
+func (program Program) IsExported(functionName string) bool {
+	for _, function := range program.Functions {
+		if function.Name == functionName && function.Visibility != "" {
+			return function.Visibility == PublicVisibility
+		}
+	}
+	if program.Exports == nil {
+		return true
+	}
+	for _, exported := range program.Exports {
+		if exported == functionName {
+			return true
+		}
+	}
+	return false
+}
+
+// ExportedFunctions returns the declared functions which can be called from the programs importing the module,
+// in program order
+func (program Program) ExportedFunctions() []Function {
+	functions := []Function{}
+	for _, function := range program.Functions {
+		if program.IsExported(function.Name) {
+			functions = append(functions, function)
+		}
+	}
+	return functions
+}
+
+// QualifiedName returns the name calling a function of a module from another program, e.g. geometry.area
+func QualifiedName(module string, functionName string) string {
+	return module + "." + functionName
+}
+
+// SplitQualifiedName returns the module and the function of a qualified name. The module is empty for a name
+// without a dot.
+func SplitQualifiedName(name string) (module string, functionName string) {
+	dot := strings.LastIndex(name, ".")
+	if dot < 0 {
+		return "", name
+	}
+	return name[:dot], name[dot+1:]
+}
+
+// ImportCycle returns the chain of imports leading from the module back to itself, e.g. [a b a], or nil if the
+// module isn't part of a cycle. Only the loaded modules are followed.
+func (modules Modules) ImportCycle(module string) []string {
+	visited := make(set)
+	// path holds the modules imported from the given one up to the current one, depth first
+	var search func(path []string) []string
+	search = func(path []string) []string {
+		for _, imported := range modules[path[len(path)-1]].Imports {
+			if imported == module {
+				return append(append([]string{}, path...), module)
+			}
+			if _, loaded := modules[imported]; !loaded {
+				continue
+			}
+			if _, seen := visited[imported]; seen {
+				continue
+			}
+			visited.add(imported)
+			if cycle := search(append(path, imported)); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+	return search([]string{module})
+}
+
+// Names returns the names of the loaded modules, sorted
+func (modules Modules) Names() []string {
+	names := make([]string, 0, len(modules))
+	for name := range modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// APIFunction describes a function exported by a module
+type APIFunction struct {
+	Module        string
+	Name          string
+	Parameters    []string // names of the parameters, which named arguments refer to
+	RequiredArity int      // number of parameters without a default value
+	Arity         int      // total number of parameters
+}
+
+// String writes the function on a single line, e.g. geometry.area(width, height?) arity 1-2, where the optional
+// parameters are followed by a question mark
+func (f APIFunction) String() string {
+	parameters := make([]string, len(f.Parameters))
+	for i, param := range f.Parameters {
+		parameters[i] = param
+		if i >= f.RequiredArity {
+			parameters[i] += "?"
+		}
+	}
+	arity := fmt.Sprint(f.Arity)
+	if f.RequiredArity != f.Arity {
+		arity = fmt.Sprintf("%v-%v", f.RequiredArity, f.Arity)
+	}
+	return fmt.Sprintf("%v(%v) arity %v", QualifiedName(f.Module, f.Name), strings.Join(parameters, ", "), arity)
+}
+
+// API returns the functions exported by the module, sorted by name, so that the APIs of two releases can be diffed
+func (program Program) API() []APIFunction {
+	api := []APIFunction{}
+	for _, function := range program.ExportedFunctions() {
+		parameters := make([]string, len(function.Parameters))
+		for i, param := range function.Parameters {
+			parameters[i] = param.Name
+		}
+		api = append(api, APIFunction{Module: program.Module, Name: function.Name, Parameters: parameters,
+			RequiredArity: function.RequiredArity(), Arity: len(function.Parameters)})
+	}
+	sort.SliceStable(api, func(i, j int) bool {
+		return api[i].Name < api[j].Name
+	})
+	return api
+}
+
+// callableFunctions returns the functions a program can call: its own functions, keyed by their name, and the
+// exported functions of the modules it imports, keyed by their qualified name
+func callableFunctions(program Program, modules Modules) map[string]Function {
+	functionMap := make(map[string]Function)
+	for _, module := range program.Imports {
+		imported, loaded := modules[module]
+		if !loaded {
+			continue
+		}
+		for _, function := range imported.ExportedFunctions() {
+			functionMap[QualifiedName(module, function.Name)] = function
+		}
+	}
+	// a function whose name contains a dot is called as such, even if it looks like a qualified name
+	for _, function := range program.Functions {
+		functionMap[function.Name] = function
+	}
+	return functionMap
+}
+
+// ValidateModule validates a program which may import modules, as ValidateProgram does. Moreover:
+//   - the imported modules must be loaded, and must not import the program back, see ImportCycle.
+//   - the exported functions must be declared, and must not be private.
+//   - the visibility of a function must be public, private or empty.
+//   - a qualified call must refer to an imported module, and to a function which the module exports.
+func ValidateModule(program Program, modules Modules, policy NumericPolicy, verbose bool) bool {
+	if !ValidateImports(program, modules, verbose) {
+		return false
+	}
+	return validateFunctions(program, callableFunctions(program, modules), policy, verbose)
+}
+
+// ValidateImports checks the module declarations of a program, and the qualified calls it contains
+func ValidateImports(program Program, modules Modules, verbose bool) bool {
+	declared := make(map[string]bool)
+	private := make(map[string]bool)
+	for _, function := range program.Functions {
+		declared[function.Name] = true
+		private[function.Name] = private[function.Name] || function.Visibility == PrivateVisibility
+		if function.Visibility != "" && function.Visibility != PublicVisibility && function.Visibility != PrivateVisibility {
+			if verbose {
+				fmt.Printf("Invalid function due to unknown visibility: %v. Function: %v\n", function.Visibility, function.Name)
+			}
+			return false
+		}
+	}
+	for _, exported := range program.Exports {
+		if !declared[exported] {
+			if verbose {
+				fmt.Println("Invalid module due to exporting undefined function: ", exported)
+			}
+			return false
+		}
+		if private[exported] {
+			if verbose {
+				fmt.Println("Invalid module due to exporting private function: ", exported)
+			}
+			return false
+		}
+	}
+
+	imported := make(map[string]bool)
+	for _, module := range program.Imports {
+		if _, loaded := modules[module]; !loaded || module == program.Module {
+			if verbose {
+				fmt.Println("Invalid import due to importing unknown module: ", module)
+			}
+			return false
+		}
+		imported[module] = true
+	}
+	if program.Module != "" {
+		if cycle := modules.ImportCycle(program.Module); cycle != nil {
+			if verbose {
+				fmt.Println("Invalid import due to import cycle: ", strings.Join(cycle, " -> "))
+			}
+			return false
+		}
+	}
+
+	// the qualified calls are reported with their reason, the other calls are left to IsValidFunctionCall
+	valid := true
+	rewriteProgram(program, func(statement *Statement, path string) {
+		if !valid || statement.Type != "function_call" || declared[statement.CalledFunction] {
+			return
+		}
+		module, functionName := SplitQualifiedName(statement.CalledFunction)
+		if module == "" {
+			return
+		}
+		switch {
+		case !imported[module]:
+			if verbose {
+				fmt.Printf("Invalid function call due to calling a function of a module which isn't imported. Function: %v at %v\n", statement.CalledFunction, path)
+			}
+			valid = false
+		case !modules[module].declares(functionName):
+			if verbose {
+				fmt.Printf("Invalid function call due to calling undefined function of module: %v. Function: %v at %v\n", module, statement.CalledFunction, path)
+			}
+			valid = false
+		case !modules[module].IsExported(functionName):
+			if verbose {
+				fmt.Printf("Invalid function call due to calling private function of module: %v. Function: %v at %v\n", module, statement.CalledFunction, path)
+			}
+			valid = false
+		}
+	})
+	return valid
+}
+
+// declares returns true if the program declares the function
+func (program Program) declares(functionName string) bool {
+	for _, function := range program.Functions {
+		if function.Name == functionName {
+			return true
+		}
+	}
+	return false
+}
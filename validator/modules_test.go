@@ -0,0 +1,164 @@
+package validator
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// loadModuleTestCases returns the programs of data/modules, keyed by their file name, along with their modules
+func loadModuleTestCases(t *testing.T) (map[string]Program, Modules) {
+	filePaths, err := filepath.Glob("../data/modules/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	programs := make(map[string]Program)
+	loaded := []Program{}
+	for _, filePath := range filePaths {
+		program := ReadTestCaseFromJSON(filePath)
+		programs[filepath.Base(filePath)] = program
+		loaded = append(loaded, program)
+	}
+	modules, err := LoadModules(loaded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return programs, modules
+}
+
+func TestValidateModule(t *testing.T) {
+	programs, modules := loadModuleTestCases(t)
+	testCases := map[string]bool{
+		"app.json":                   true,
+		"geometry.json":              true,
+		"units.json":                 true,
+		"shapes.json":                true,
+		"private_call.json":          false, // geometry.checked isn't exported
+		"private_function_call.json": false, // shapes.half is declared private
+		"not_imported.json":          false, // units.meters is called without importing units
+		"cycle_a.json":               false,
+		"cycle_b.json":               false,
+	}
+	for fileName, expected := range testCases {
+		if result := ValidateModule(programs[fileName], modules, PermissiveNumericPolicy, false); result != expected {
+			t.Errorf("%v: unexpected result. Got %v, want %v", fileName, result, expected)
+		}
+	}
+
+	// the imported modules must be loaded
+	if ValidateProgram(programs["app.json"], PermissiveNumericPolicy, false) {
+		t.Errorf("A program importing modules which aren't loaded should be invalid")
+	}
+	// the exported functions must be declared
+	geometry := programs["geometry.json"]
+	geometry.Exports = []string{"area", "volume"}
+	if ValidateModule(geometry, modules, PermissiveNumericPolicy, false) {
+		t.Errorf("A module exporting an undeclared function should be invalid")
+	}
+	// a private function can't be exported, and the visibility must be known
+	geometry = programs["geometry.json"]
+	geometry.Functions = append([]Function{}, geometry.Functions...)
+	geometry.Functions[0].Visibility = PrivateVisibility
+	if ValidateModule(geometry, modules, PermissiveNumericPolicy, false) {
+		t.Errorf("A module exporting a private function should be invalid")
+	}
+	geometry.Functions[0].Visibility = "internal"
+	if ValidateModule(geometry, modules, PermissiveNumericPolicy, false) {
+		t.Errorf("A function with an unknown visibility should be invalid")
+	}
+	// the calls to an imported function are checked against its parameters
+	app := rewriteProgram(programs["app.json"], func(statement *Statement, path string) {
+		if statement.CalledFunction == "geometry.area" {
+			statement.Arguments = []Statement{}
+		}
+	})
+	if ValidateModule(app, modules, PermissiveNumericPolicy, false) {
+		t.Errorf("A call to an imported function missing an argument should be invalid")
+	}
+}
+
+func TestLoadModules_Duplicate(t *testing.T) {
+	programs := []Program{{Module: "units"}, {}, {Module: "units"}}
+	if _, err := LoadModules(programs); !errors.Is(err, ErrDuplicateModule) {
+		t.Errorf("Unexpected error. Got %v, want %v", err, ErrDuplicateModule)
+	}
+}
+
+func TestModules_ImportCycle(t *testing.T) {
+	modules := Modules{
+		"a": {Module: "a", Imports: []string{"b"}},
+		"b": {Module: "b", Imports: []string{"c", "missing"}},
+		"c": {Module: "c", Imports: []string{"a"}},
+		"d": {Module: "d", Imports: []string{"a"}},
+	}
+	testCases := map[string][]string{
+		"a": {"a", "b", "c", "a"},
+		"c": {"c", "a", "b", "c"},
+		"d": nil, // d imports a cycle, but isn't part of it
+	}
+	for module, expected := range testCases {
+		if cycle := modules.ImportCycle(module); !reflect.DeepEqual(cycle, expected) {
+			t.Errorf("%v: unexpected cycle. Got %v, want %v", module, cycle, expected)
+		}
+	}
+}
+
+func TestProgram_IsExported(t *testing.T) {
+	program := Program{Functions: []Function{{Name: "area"}, {Name: "checked"}}}
+	if !program.IsExported("checked") {
+		t.Errorf("Every function should be exported when the exports are omitted")
+	}
+	program.Exports = []string{"area"}
+	if !program.IsExported("area") || program.IsExported("checked") {
+		t.Errorf("Only the listed functions should be exported")
+	}
+	program.Exports = []string{}
+	if program.IsExported("area") {
+		t.Errorf("No function should be exported by an empty list")
+	}
+	// the visibility of a function takes precedence over the exports
+	program.Functions[0].Visibility = PublicVisibility
+	program.Exports = nil
+	program.Functions[1].Visibility = PrivateVisibility
+	if !program.IsExported("area") || program.IsExported("checked") {
+		t.Errorf("The visibility of the functions should be followed")
+	}
+}
+
+func TestProgram_API(t *testing.T) {
+	programs, _ := loadModuleTestCases(t)
+	expected := []string{
+		"shapes.square(side) arity 1",
+		"shapes.triangle(base, height?) arity 1-2",
+	}
+	api := programs["shapes.json"].API()
+	result := make([]string, len(api))
+	for i, function := range api {
+		result[i] = function.String()
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Unexpected result. Got %v, want %v", result, expected)
+	}
+}
+
+func TestParseProgram_Modules(t *testing.T) {
+	source := "module geometry;\nimport units;\nimport math;\nexport;\n\npublic func area(width) {\n    units.meters(width);\n}\n"
+	expectedResult := Program{Module: "geometry", Imports: []string{"units", "math"}, Exports: []string{}, Functions: []Function{
+		{Name: "area", Visibility: PublicVisibility, Parameters: []Parameter{{Name: "width"}}, Body: Block{Statements: []Statement{
+			{Type: "function_call", CalledFunction: "units.meters", Arguments: []Statement{{Type: "variable", Variable: "width"}}},
+		}}},
+	}}
+
+	result, err := ParseProgram(source)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, expectedResult) {
+		t.Errorf("Unexpected result. Got %v, want %v", result, expectedResult)
+	}
+	printed := PrintProgram(result, PrintOptions{})
+	if expected := "module geometry;\nimport units, math;\nexport;\n\npublic func area(width) {\n    units.meters(width);\n}\n"; printed != expected {
+		t.Errorf("Unexpected printed program. Got\n%v\nwant\n%v", printed, expected)
+	}
+}
@@ -0,0 +1,100 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// -----------------------------------------
+// Numeric literal policies
+// -----------------------------------------
+
+// Errors reported for numerical literals rejected by a NumericPolicy
+var (
+	ErrNotNumerical         = errors.New("value couldn't be converted to a number")
+	ErrFloatOutOfRange      = errors.New("value is out of the float64 range")
+	ErrHexadecimal          = errors.New("hexadecimal values are not allowed")
+	ErrNaN                  = errors.New("NaN is not allowed")
+	ErrInfinite             = errors.New("infinite values are not allowed")
+	ErrNotInteger           = errors.New("value is not an integer")
+	ErrIntegerOutOfRange    = errors.New("value is out of the int64 range")
+	ErrUnknownNumericPolicy = errors.New("unknown numeric policy")
+)
+
+// NumericPolicy restricts the values accepted for numerical literals
+type NumericPolicy struct {
+	Name            string
+	CheckRange      bool // reject values overflowing float64, such as 1e400
+	RejectHex       bool // reject hexadecimal values, such as 0x1p-2
+	RejectNonFinite bool // reject NaN and Inf
+	RequireInteger  bool // only accept decimal integers fitting in an int64
+}
+
+var (
+	// PermissiveNumericPolicy accepts any value strconv.ParseFloat accepts, including NaN, Inf and 1e400
+	PermissiveNumericPolicy = NumericPolicy{Name: "permissive"}
+	// FiniteNumericPolicy only accepts finite float64 values written in decimal
+	FiniteNumericPolicy = NumericPolicy{Name: "finite", CheckRange: true, RejectHex: true, RejectNonFinite: true}
+	// IntegerNumericPolicy only accepts int64 values written in decimal
+	IntegerNumericPolicy = NumericPolicy{Name: "integer", CheckRange: true, RejectHex: true, RejectNonFinite: true, RequireInteger: true}
+)
+
+// NumericPolicies lists the predefined policies, keyed by their name
+var NumericPolicies = map[string]NumericPolicy{
+	PermissiveNumericPolicy.Name: PermissiveNumericPolicy,
+	FiniteNumericPolicy.Name:     FiniteNumericPolicy,
+	IntegerNumericPolicy.Name:    IntegerNumericPolicy,
+}
+
+// NumericPolicyByName returns the predefined policy with the given name
+func NumericPolicyByName(name string) (NumericPolicy, error) {
+	policy, ok := NumericPolicies[name]
+	if !ok {
+		return NumericPolicy{}, fmt.Errorf("%w: %v", ErrUnknownNumericPolicy, name)
+	}
+	return policy, nil
+}
+
+// CheckNumericalLiteral returns an error if the value of a numerical literal is rejected by the policy.
+// The returned error wraps one of the ErrXxx errors above, so each rejection can be told apart using errors.Is.
+func (policy NumericPolicy) CheckNumericalLiteral(value string) error {
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		// a value overflowing float64 is syntactically valid and parsed as +-Inf
+		if !errors.Is(err, strconv.ErrRange) {
+			return fmt.Errorf("%w: %v", ErrNotNumerical, value)
+		}
+		if policy.CheckRange {
+			return fmt.Errorf("%w: %v", ErrFloatOutOfRange, value)
+		}
+	}
+	if policy.RejectHex && isHexadecimal(value) {
+		return fmt.Errorf("%w: %v", ErrHexadecimal, value)
+	}
+	if policy.RejectNonFinite {
+		if math.IsNaN(number) {
+			return fmt.Errorf("%w: %v", ErrNaN, value)
+		}
+		if math.IsInf(number, 0) {
+			return fmt.Errorf("%w: %v", ErrInfinite, value)
+		}
+	}
+	if policy.RequireInteger {
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			if errors.Is(err, strconv.ErrRange) {
+				return fmt.Errorf("%w: %v", ErrIntegerOutOfRange, value)
+			}
+			return fmt.Errorf("%w: %v", ErrNotInteger, value)
+		}
+	}
+	return nil
+}
+
+// isHexadecimal checks if a number is written with the 0x prefix
+func isHexadecimal(value string) bool {
+	value = strings.TrimLeft(value, "+-")
+	return strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0X")
+}
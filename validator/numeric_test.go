@@ -0,0 +1,69 @@
+package validator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckNumericalLiteral(t *testing.T) {
+	testCases := []struct {
+		policy   NumericPolicy
+		value    string
+		expected error
+	}{
+		{PermissiveNumericPolicy, "10", nil},
+		{PermissiveNumericPolicy, "NaN", nil},
+		{PermissiveNumericPolicy, "1e400", nil},
+		{PermissiveNumericPolicy, "0x1p-2", nil},
+		{PermissiveNumericPolicy, "ten", ErrNotNumerical},
+		{FiniteNumericPolicy, "2.5", nil},
+		{FiniteNumericPolicy, "-1e300", nil},
+		{FiniteNumericPolicy, "1e400", ErrFloatOutOfRange},
+		{FiniteNumericPolicy, "0x1p-2", ErrHexadecimal},
+		{FiniteNumericPolicy, "NaN", ErrNaN},
+		{FiniteNumericPolicy, "-Inf", ErrInfinite},
+		{IntegerNumericPolicy, "-9223372036854775808", nil},
+		{IntegerNumericPolicy, "9223372036854775808", ErrIntegerOutOfRange},
+		{IntegerNumericPolicy, "2.5", ErrNotInteger},
+		{IntegerNumericPolicy, "1e3", ErrNotInteger},
+		{IntegerNumericPolicy, "0x1p4", ErrHexadecimal},
+	}
+	for _, testCase := range testCases {
+		err := testCase.policy.CheckNumericalLiteral(testCase.value)
+		if (testCase.expected == nil && err != nil) || !errors.Is(err, testCase.expected) {
+			t.Errorf("%v policy: CheckNumericalLiteral(%q) = %v, want %v", testCase.policy.Name, testCase.value, err, testCase.expected)
+		}
+	}
+}
+
+func TestNumericPolicyByName(t *testing.T) {
+	policy, err := NumericPolicyByName("integer")
+	if err != nil || policy != IntegerNumericPolicy {
+		t.Errorf("Unexpected result. Got %v, %v, want %v", policy, err, IntegerNumericPolicy)
+	}
+	if _, err := NumericPolicyByName("decimal"); !errors.Is(err, ErrUnknownNumericPolicy) {
+		t.Errorf("Unexpected error. Got %v, want %v", err, ErrUnknownNumericPolicy)
+	}
+}
+
+func TestValidateProgram_NumericPolicies(t *testing.T) {
+	testCases := []struct {
+		filepath string
+		policy   NumericPolicy
+		expected bool
+	}{
+		{"../data/numeric/float_literals.json", FiniteNumericPolicy, true},
+		{"../data/numeric/float_literals.json", IntegerNumericPolicy, false},
+		{"../data/numeric/overflowing_literal.json", PermissiveNumericPolicy, true},
+		{"../data/numeric/overflowing_literal.json", FiniteNumericPolicy, false},
+		{"../data/numeric/not_a_number_literal.json", PermissiveNumericPolicy, true},
+		{"../data/numeric/not_a_number_literal.json", FiniteNumericPolicy, false},
+	}
+	for _, testCase := range testCases {
+		program := ReadTestCaseFromJSON(testCase.filepath)
+		result := ValidateProgram(program, testCase.policy, false)
+		if result != testCase.expected {
+			t.Errorf("%v with %v policy: got %v, want %v", testCase.filepath, testCase.policy.Name, result, testCase.expected)
+		}
+	}
+}
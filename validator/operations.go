@@ -0,0 +1,331 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// -----------------------------------------
+// Operation registry
+// -----------------------------------------
+
+// OperandConstraint describes which types an operation accepts for its operands
+type OperandConstraint int
+
+const (
+	AnyOperand     OperandConstraint = iota // any type, all operands must have the same type
+	NumericOperand                          // int or float
+	IntegerOperand                          // int only
+	BoolOperand                             // bool only
+	StringOperand                           // string only
+)
+
+// OperationSpec describes an operation type of the language
+type OperationSpec struct {
+	Name        string            // value of "operation_type"
+	MinOperands int               // minimum number of operands
+	MaxOperands int               // maximum number of operands, -1 for no limit
+	Operands    OperandConstraint // constraint on the types of the operands
+	// Result is the type of the operation. TypeUnknown means the result has the (promoted) type of the operands,
+	// e.g. the addition of an int and a float is a float.
+	Result Type
+	// Evaluate computes the value of the operation from the values of its operands, see EvaluateOperation
+	Evaluate func(operands []Value) (Value, error)
+	// Symbol is the operator of the operation in the textual syntax, see PrintProgram. Operations with a single
+	// operand are prefix operators, the others are infix operators.
+	Symbol string
+	// Precedence orders the operators of the textual syntax, operators with a higher precedence bind tighter
+	Precedence int
+}
+
+// Operations is the registry of supported operation types, keyed by their name
+var Operations = map[string]OperationSpec{
+	// the first operand of an assignment is the assigned variable, the second one is the assigned value.
+	// The interpreter assigns the value to the variable, the operation evaluates to the assigned value.
+	"assignment": {Name: "assignment", Symbol: "=", Precedence: 1, MinOperands: 2, MaxOperands: 2, Operands: AnyOperand,
+		Evaluate: func(operands []Value) (Value, error) { return operands[1], nil }},
+
+	// arithmetic
+	"addition": {Name: "addition", Symbol: "+", Precedence: 6, MinOperands: 2, MaxOperands: -1, Operands: NumericOperand,
+		Evaluate: arithmetic("addition", addInt, func(a, b float64) (float64, error) { return a + b, nil })},
+	"subtraction": {Name: "subtraction", Symbol: "-", Precedence: 6, MinOperands: 2, MaxOperands: 2, Operands: NumericOperand,
+		Evaluate: arithmetic("subtraction", subtractInt, func(a, b float64) (float64, error) { return a - b, nil })},
+	"multiplication": {Name: "multiplication", Symbol: "*", Precedence: 7, MinOperands: 2, MaxOperands: -1, Operands: NumericOperand,
+		Evaluate: arithmetic("multiplication", multiplyInt, func(a, b float64) (float64, error) { return a * b, nil })},
+	"division": {Name: "division", Symbol: "/", Precedence: 7, MinOperands: 2, MaxOperands: 2, Operands: NumericOperand,
+		Evaluate: arithmetic("division", divideInt, divideFloat)},
+	"modulo": {Name: "modulo", Symbol: "%", Precedence: 7, MinOperands: 2, MaxOperands: 2, Operands: IntegerOperand,
+		Evaluate: arithmetic("modulo", moduloInt, nil)},
+	"negation": {Name: "negation", Symbol: "-", Precedence: 8, MinOperands: 1, MaxOperands: 1, Operands: NumericOperand,
+		Evaluate: negate},
+
+	// comparison
+	"equal": {Name: "equal", Symbol: "==", Precedence: 4, MinOperands: 2, MaxOperands: 2, Operands: AnyOperand, Result: TypeBool,
+		Evaluate: compareEquality("equal", true)},
+	"not_equal": {Name: "not_equal", Symbol: "!=", Precedence: 4, MinOperands: 2, MaxOperands: 2, Operands: AnyOperand, Result: TypeBool,
+		Evaluate: compareEquality("not_equal", false)},
+	"less_than": {Name: "less_than", Symbol: "<", Precedence: 5, MinOperands: 2, MaxOperands: 2, Operands: NumericOperand, Result: TypeBool,
+		Evaluate: compareOrder("less_than", func(a, b float64) bool { return a < b })},
+	"less_equal": {Name: "less_equal", Symbol: "<=", Precedence: 5, MinOperands: 2, MaxOperands: 2, Operands: NumericOperand, Result: TypeBool,
+		Evaluate: compareOrder("less_equal", func(a, b float64) bool { return a <= b })},
+	"greater_than": {Name: "greater_than", Symbol: ">", Precedence: 5, MinOperands: 2, MaxOperands: 2, Operands: NumericOperand, Result: TypeBool,
+		Evaluate: compareOrder("greater_than", func(a, b float64) bool { return a > b })},
+	"greater_equal": {Name: "greater_equal", Symbol: ">=", Precedence: 5, MinOperands: 2, MaxOperands: 2, Operands: NumericOperand, Result: TypeBool,
+		Evaluate: compareOrder("greater_equal", func(a, b float64) bool { return a >= b })},
+
+	// logic
+	"and": {Name: "and", Symbol: "&&", Precedence: 3, MinOperands: 2, MaxOperands: -1, Operands: BoolOperand, Result: TypeBool,
+		Evaluate: logic("and", func(a, b bool) bool { return a && b })},
+	"or": {Name: "or", Symbol: "||", Precedence: 2, MinOperands: 2, MaxOperands: -1, Operands: BoolOperand, Result: TypeBool,
+		Evaluate: logic("or", func(a, b bool) bool { return a || b })},
+	"not": {Name: "not", Symbol: "!", Precedence: 8, MinOperands: 1, MaxOperands: 1, Operands: BoolOperand, Result: TypeBool,
+		Evaluate: logicalNot},
+
+	// strings
+	"concatenation": {Name: "concatenation", Symbol: "++", Precedence: 6, MinOperands: 2, MaxOperands: -1, Operands: StringOperand, Result: TypeString,
+		Evaluate: concatenate},
+}
+
+// AcceptsOperandCount checks if the operation accepts the given number of operands
+func (spec OperationSpec) AcceptsOperandCount(count int) bool {
+	return count >= spec.MinOperands && (spec.MaxOperands < 0 || count <= spec.MaxOperands)
+}
+
+// Accepts checks if an operand of the given type satisfies the constraint.
+// Operands of unknown type are always accepted.
+func (constraint OperandConstraint) Accepts(t Type) bool {
+	if t == TypeUnknown {
+		return true
+	}
+	switch constraint {
+	case NumericOperand:
+		return t == TypeInt || t == TypeFloat
+	case IntegerOperand:
+		return t == TypeInt
+	case BoolOperand:
+		return t == TypeBool
+	case StringOperand:
+		return t == TypeString
+	}
+	return true
+}
+
+// String returns a description of the constraint used in diagnostics
+func (constraint OperandConstraint) String() string {
+	switch constraint {
+	case NumericOperand:
+		return "numeric"
+	case IntegerOperand:
+		return "int"
+	case BoolOperand:
+		return "bool"
+	case StringOperand:
+		return "string"
+	}
+	return "any"
+}
+
+// -----------------------------------------
+// Operation semantics
+// -----------------------------------------
+
+// Errors reported when evaluating operations
+var (
+	ErrDivisionByZero  = errors.New("division by zero")
+	ErrIntegerOverflow = errors.New("integer overflow")
+	ErrFloatOverflow   = errors.New("float overflow")
+	ErrOperandType     = errors.New("invalid operand type")
+)
+
+// EvaluateOperation applies an operation of the registry to the values of its operands.
+// All operands are evaluated before the operation is applied, so "and" and "or" don't short-circuit.
+func EvaluateOperation(operationType string, operands []Value) (Value, error) {
+	spec, known := Operations[operationType]
+	if !known || spec.Evaluate == nil {
+		return Void, fmt.Errorf("unknown operation type: %v", operationType)
+	}
+	if !spec.AcceptsOperandCount(len(operands)) {
+		return Void, fmt.Errorf("operation: %v doesn't accept %v operands", operationType, len(operands))
+	}
+	return spec.Evaluate(operands)
+}
+
+func operandTypeError(operationType string, operand Value) error {
+	return fmt.Errorf("%w: operation: %v doesn't accept %v operand %v", ErrOperandType, operationType, operand.Type, operand)
+}
+
+// arithmetic folds the operands from left to right. Integers are combined using intOp,
+// otherwise the operands are promoted to float and combined using floatOp (nil if floats are not accepted).
+func arithmetic(name string, intOp func(a, b int64) (int64, error), floatOp func(a, b float64) (float64, error)) func([]Value) (Value, error) {
+	return func(operands []Value) (Value, error) {
+		result := operands[0]
+		if !result.IsNumeric() || (floatOp == nil && result.Type != TypeInt) {
+			return Void, operandTypeError(name, result)
+		}
+		for _, operand := range operands[1:] {
+			switch {
+			case result.Type == TypeInt && operand.Type == TypeInt:
+				value, err := intOp(result.Int, operand.Int)
+				if err != nil {
+					return Void, err
+				}
+				result = IntValue(value)
+			case floatOp != nil && operand.IsNumeric():
+				value, err := floatOp(result.AsFloat(), operand.AsFloat())
+				if err != nil {
+					return Void, err
+				}
+				if math.IsInf(value, 0) || math.IsNaN(value) {
+					return Void, fmt.Errorf("%w: %v of %v and %v", ErrFloatOverflow, name, result, operand)
+				}
+				result = FloatValue(value)
+			default:
+				return Void, operandTypeError(name, operand)
+			}
+		}
+		return result, nil
+	}
+}
+
+func addInt(a, b int64) (int64, error) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, fmt.Errorf("%w: %v + %v", ErrIntegerOverflow, a, b)
+	}
+	return a + b, nil
+}
+
+func subtractInt(a, b int64) (int64, error) {
+	if (b < 0 && a > math.MaxInt64+b) || (b > 0 && a < math.MinInt64+b) {
+		return 0, fmt.Errorf("%w: %v - %v", ErrIntegerOverflow, a, b)
+	}
+	return a - b, nil
+}
+
+func multiplyInt(a, b int64) (int64, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	result := a * b
+	if result/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, fmt.Errorf("%w: %v * %v", ErrIntegerOverflow, a, b)
+	}
+	return result, nil
+}
+
+func divideInt(a, b int64) (int64, error) {
+	if b == 0 {
+		return 0, fmt.Errorf("%w: %v / %v", ErrDivisionByZero, a, b)
+	}
+	if a == math.MinInt64 && b == -1 {
+		return 0, fmt.Errorf("%w: %v / %v", ErrIntegerOverflow, a, b)
+	}
+	return a / b, nil
+}
+
+func divideFloat(a, b float64) (float64, error) {
+	if b == 0 {
+		return 0, fmt.Errorf("%w: %v / %v", ErrDivisionByZero, a, b)
+	}
+	return a / b, nil
+}
+
+func moduloInt(a, b int64) (int64, error) {
+	if b == 0 {
+		return 0, fmt.Errorf("%w: %v %% %v", ErrDivisionByZero, a, b)
+	}
+	if b == -1 {
+		// avoids the overflow of math.MinInt64 % -1
+		return 0, nil
+	}
+	return a % b, nil
+}
+
+func negate(operands []Value) (Value, error) {
+	operand := operands[0]
+	switch operand.Type {
+	case TypeInt:
+		if operand.Int == math.MinInt64 {
+			return Void, fmt.Errorf("%w: -(%v)", ErrIntegerOverflow, operand.Int)
+		}
+		return IntValue(-operand.Int), nil
+	case TypeFloat:
+		return FloatValue(-operand.Float), nil
+	}
+	return Void, operandTypeError("negation", operand)
+}
+
+// compareEquality compares two numbers after promoting them to float, or two values of the same type
+func compareEquality(name string, equal bool) func([]Value) (Value, error) {
+	return func(operands []Value) (Value, error) {
+		a, b := operands[0], operands[1]
+		var same bool
+		switch {
+		case a.Type == TypeInt && b.Type == TypeInt:
+			same = a.Int == b.Int
+		case a.IsNumeric() && b.IsNumeric():
+			same = a.AsFloat() == b.AsFloat()
+		case a.Type != b.Type || a.Type == TypeUnknown:
+			return Void, operandTypeError(name, b)
+		default:
+			same = a == b
+		}
+		return BoolValue(same == equal), nil
+	}
+}
+
+func compareOrder(name string, compare func(a, b float64) bool) func([]Value) (Value, error) {
+	return func(operands []Value) (Value, error) {
+		a, b := operands[0], operands[1]
+		for _, operand := range operands {
+			if !operand.IsNumeric() {
+				return Void, operandTypeError(name, operand)
+			}
+		}
+		if a.Type == TypeInt && b.Type == TypeInt {
+			// compare integers exactly, since large values lose precision as floats
+			switch {
+			case a.Int < b.Int:
+				return BoolValue(compare(0, 1)), nil
+			case a.Int > b.Int:
+				return BoolValue(compare(1, 0)), nil
+			}
+			return BoolValue(compare(0, 0)), nil
+		}
+		return BoolValue(compare(a.AsFloat(), b.AsFloat())), nil
+	}
+}
+
+func logic(name string, combine func(a, b bool) bool) func([]Value) (Value, error) {
+	return func(operands []Value) (Value, error) {
+		for _, operand := range operands {
+			if operand.Type != TypeBool {
+				return Void, operandTypeError(name, operand)
+			}
+		}
+		result := operands[0].Bool
+		for _, operand := range operands[1:] {
+			result = combine(result, operand.Bool)
+		}
+		return BoolValue(result), nil
+	}
+}
+
+func logicalNot(operands []Value) (Value, error) {
+	if operands[0].Type != TypeBool {
+		return Void, operandTypeError("not", operands[0])
+	}
+	return BoolValue(!operands[0].Bool), nil
+}
+
+func concatenate(operands []Value) (Value, error) {
+	var builder strings.Builder
+	for _, operand := range operands {
+		if operand.Type != TypeString {
+			return Void, operandTypeError("concatenation", operand)
+		}
+		builder.WriteString(operand.Text)
+	}
+	return StringValue(builder.String()), nil
+}
@@ -0,0 +1,417 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// -----------------------------------------
+// Parser of the textual syntax
+// -----------------------------------------
+/*
+	ParseProgram reads a program written in the textual syntax, the pseudo-code written by PrintProgram:
+
+		program    = [ "module" name ";" ] { "import" names ";" } [ "export" [ names ] ";" ] { function }
+		names      = name { "," name }
+		name       = identifier | "#" string
+		function   = [ "public" | "private" ] "func" name "(" [ parameter { "," parameter } ] ")" block
+		parameter  = name [ "=" expression ]
+		block      = "{" { statement } "}"
+		statement  = ";" | block | "var" name ";" | expression ";"
+		expression = unary { operator unary }
+		unary      = prefix-operator unary | primary
+		primary    = number | string | "true" | "false" | name | [ name "." ] name "(" [ arguments ] ")"
+		           | "(" expression ")" | "#" type string | "#" operation "(" [ arguments ] ")"
+		arguments  = argument { "," argument }
+		argument   = [ name ":" ] expression
+
+	The operators and their precedence are taken from the operation registry. Assignments group to the right, the
+	other operations to the left, and a chain of the same operation without parentheses, e.g. a + b + c, is a
+	single operation when the registry allows more than 2 operands. Strings keep their escape sequences, as in JSON,
+	while the values of the raw forms, and the raw names, e.g. #"import", are Go string literals.
+	ParseProgram(PrintProgram(program)) returns the same program.
+*/
+
+// ParseProgram returns the program written in the textual syntax, or a *SyntaxError
+func ParseProgram(source string) (Program, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return Program{}, err
+	}
+	p := parser{tokens: tokens, prefix: map[string]string{}, infix: map[string]string{}}
+	for operationType, spec := range Operations {
+		if spec.Symbol == "" {
+			continue
+		}
+		if spec.MaxOperands == 1 {
+			p.prefix[spec.Symbol] = operationType
+		} else {
+			p.infix[spec.Symbol] = operationType
+		}
+	}
+
+	program := Program{Functions: []Function{}}
+	if p.is("module") {
+		p.position++
+		if program.Module, err = p.identifier("the name of the module"); err != nil {
+			return Program{}, err
+		}
+		if err := p.expect(";", "after the name of the module"); err != nil {
+			return Program{}, err
+		}
+	}
+	for p.is("import") {
+		p.position++
+		imports, err := p.names("the name of an imported module")
+		if err != nil {
+			return Program{}, err
+		}
+		if err := p.expect(";", "at the end of the imports"); err != nil {
+			return Program{}, err
+		}
+		program.Imports = append(program.Imports, imports...)
+	}
+	if p.is("export") {
+		p.position++
+		program.Exports = []string{}
+		if !p.is(";") {
+			if program.Exports, err = p.names("the name of an exported function"); err != nil {
+				return Program{}, err
+			}
+		}
+		if err := p.expect(";", "at the end of the exports"); err != nil {
+			return Program{}, err
+		}
+	}
+	for p.current().kind != tokenEOF {
+		function, err := p.function()
+		if err != nil {
+			return Program{}, err
+		}
+		program.Functions = append(program.Functions, function)
+	}
+	return program, nil
+}
+
+// parser holds the state of ParseProgram
+type parser struct {
+	tokens   []token
+	position int
+	prefix   map[string]string // operation type of each prefix operator
+	infix    map[string]string // operation type of each infix operator
+}
+
+func (p *parser) current() token {
+	return p.tokens[p.position]
+}
+
+// is returns true if the current token is the given symbol or keyword
+func (p *parser) is(text string) bool {
+	t := p.current()
+	return (t.kind == tokenSymbol || t.kind == tokenKeyword) && t.text == text
+}
+
+func (p *parser) errorf(t token, format string, args ...interface{}) error {
+	return &SyntaxError{Line: t.line, Column: t.column, Message: fmt.Sprintf(format, args...)}
+}
+
+// expect consumes the given symbol or keyword, context describes what is being parsed
+func (p *parser) expect(text string, context string) error {
+	if !p.is(text) {
+		return p.errorf(p.current(), "expected '%v' %v, found %v", text, context, p.current().describe())
+	}
+	p.position++
+	return nil
+}
+
+// nameLength returns the number of tokens of the name at the current token: 1 for an identifier, 2 for a raw name
+// such as #"import", 0 if the current token doesn't start a name
+func (p *parser) nameLength() int {
+	t := p.current()
+	switch {
+	case t.kind == tokenIdentifier:
+		return 1
+	case t.kind == tokenSymbol && t.text == "#" && p.tokens[p.position+1].kind == tokenString:
+		return 2
+	}
+	return 0
+}
+
+// identifier consumes a name, either an identifier or a raw name, context describes what is being parsed
+func (p *parser) identifier(context string) (string, error) {
+	t := p.current()
+	switch p.nameLength() {
+	case 1:
+		p.position++
+		return t.text, nil
+	case 2:
+		quoted := p.tokens[p.position+1]
+		p.position += 2
+		name, err := strconv.Unquote(`"` + quoted.text + `"`)
+		if err != nil {
+			return "", p.errorf(quoted, "invalid quoted name: %v", err)
+		}
+		return name, nil
+	}
+	return "", p.errorf(t, "expected %v, found %v", context, t.describe())
+}
+
+// names consumes a list of identifiers separated by commas, context describes what each identifier is
+func (p *parser) names(context string) ([]string, error) {
+	names := []string{}
+	for {
+		name, err := p.identifier(context)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if !p.is(",") {
+			break
+		}
+		p.position++
+	}
+	return names, nil
+}
+
+func (p *parser) function() (Function, error) {
+	visibility := ""
+	if p.is(PublicVisibility) || p.is(PrivateVisibility) {
+		visibility = p.current().text
+		p.position++
+	}
+	if err := p.expect("func", "at the start of a function"); err != nil {
+		return Function{}, err
+	}
+	name, err := p.identifier("the name of the function")
+	if err != nil {
+		return Function{}, err
+	}
+	function := Function{Name: name, Visibility: visibility, Parameters: []Parameter{}}
+	if err := p.expect("(", "after the name of function "+name); err != nil {
+		return Function{}, err
+	}
+	for !p.is(")") {
+		if len(function.Parameters) > 0 {
+			if err := p.expect(",", "between the parameters of function "+name); err != nil {
+				return Function{}, err
+			}
+		}
+		param := Parameter{}
+		param.Name, err = p.identifier("the name of a parameter of function " + name)
+		if err != nil {
+			return Function{}, err
+		}
+		if p.is("=") {
+			p.position++
+			value, err := p.expression(0)
+			if err != nil {
+				return Function{}, err
+			}
+			param.Default = &value
+		}
+		function.Parameters = append(function.Parameters, param)
+	}
+	p.position++
+	function.Body, err = p.block("the body of function " + name)
+	return function, err
+}
+
+// block parses a block, context describes what is being parsed
+func (p *parser) block(context string) (Block, error) {
+	if err := p.expect("{", "at the start of "+context); err != nil {
+		return Block{}, err
+	}
+	block := Block{Statements: []Statement{}}
+	for !p.is("}") {
+		if p.current().kind == tokenEOF {
+			return Block{}, p.errorf(p.current(), "expected '}' at the end of %v, found %v", context, p.current().describe())
+		}
+		statement, err := p.statement()
+		if err != nil {
+			return Block{}, err
+		}
+		block.Statements = append(block.Statements, statement)
+	}
+	p.position++
+	return block, nil
+}
+
+func (p *parser) statement() (Statement, error) {
+	switch {
+	case p.is(";"):
+		p.position++
+		return Statement{}, nil
+	case p.is("{"):
+		block, err := p.block("the block")
+		return Statement{Type: "block", Block: block}, err
+	case p.is("var"):
+		p.position++
+		name, err := p.identifier("the name of the declared variable")
+		if err != nil {
+			return Statement{}, err
+		}
+		return Statement{Type: "variable_declaration", Variable: name}, p.expect(";", "after the declaration of "+name)
+	}
+	statement, err := p.expression(0)
+	if err != nil {
+		return Statement{}, err
+	}
+	return statement, p.expect(";", "at the end of the statement")
+}
+
+// expression parses the operations whose precedence is higher than the given precedence
+func (p *parser) expression(precedence int) (Statement, error) {
+	left, grouped, err := p.unary()
+	if err != nil {
+		return Statement{}, err
+	}
+	for {
+		t := p.current()
+		operationType, isOperator := p.infix[t.text]
+		if t.kind != tokenSymbol || !isOperator {
+			return left, nil
+		}
+		spec := Operations[operationType]
+		if spec.Precedence <= precedence {
+			return left, nil
+		}
+		p.position++
+		// the right operand of an assignment may be another assignment, e.g. x = y = 1
+		rightPrecedence := spec.Precedence
+		if operationType == "assignment" {
+			rightPrecedence--
+		}
+		right, err := p.expression(rightPrecedence)
+		if err != nil {
+			return Statement{}, err
+		}
+		if !grouped && left.Type == "operation" && left.OperationType == operationType && spec.AcceptsOperandCount(len(left.Operands)+1) {
+			left.Operands = append(left.Operands, right)
+		} else {
+			left = Statement{Type: "operation", OperationType: operationType, Operands: []Statement{left, right}}
+		}
+		grouped = false
+	}
+}
+
+// unary parses an operand, possibly preceded by prefix operators. grouped is true if the operand is between
+// parentheses.
+func (p *parser) unary() (operand Statement, grouped bool, err error) {
+	t := p.current()
+	if operationType, isOperator := p.prefix[t.text]; isOperator && t.kind == tokenSymbol {
+		p.position++
+		nested, _, err := p.unary()
+		if err != nil {
+			return Statement{}, false, err
+		}
+		return Statement{Type: "operation", OperationType: operationType, Operands: []Statement{nested}}, false, nil
+	}
+	if p.is("(") {
+		p.position++
+		operand, err := p.expression(0)
+		if err != nil {
+			return Statement{}, false, err
+		}
+		return operand, true, p.expect(")", "to close the parentheses")
+	}
+	operand, err = p.primary()
+	return operand, false, err
+}
+
+// primary parses a literal, a variable, a call or a raw form
+func (p *parser) primary() (Statement, error) {
+	t := p.current()
+	if p.nameLength() > 0 {
+		name, err := p.identifier("a name")
+		if err != nil {
+			return Statement{}, err
+		}
+		if p.is(".") {
+			// a qualified call to a function of an imported module, e.g. geometry.area(x)
+			p.position++
+			functionName, err := p.identifier("the name of a function of module " + name)
+			if err != nil {
+				return Statement{}, err
+			}
+			name = QualifiedName(name, functionName)
+			if !p.is("(") {
+				return Statement{}, p.errorf(p.current(), "expected '(' after %v, found %v", name, p.current().describe())
+			}
+		}
+		if !p.is("(") {
+			return Statement{Type: "variable", Variable: name}, nil
+		}
+		arguments, err := p.arguments("the call to " + name)
+		return Statement{Type: "function_call", CalledFunction: name, Arguments: arguments}, err
+	}
+	p.position++
+	switch t.kind {
+	case tokenNumber:
+		return Statement{Type: "numerical", Value: t.text}, nil
+	case tokenString:
+		return Statement{Type: "string", Value: t.text}, nil
+	case tokenKeyword:
+		if t.text == "true" || t.text == "false" {
+			return Statement{Type: "boolean", Value: t.text}, nil
+		}
+	case tokenSymbol:
+		if t.text == "#" {
+			return p.raw()
+		}
+	}
+	return Statement{}, p.errorf(t, "expected an operand, found %v", t.describe())
+}
+
+// raw parses the raw form of a literal, e.g. #numerical"NaN", or of an operation, e.g. #addition(x)
+func (p *parser) raw() (Statement, error) {
+	name := p.current()
+	if name.kind != tokenIdentifier {
+		return Statement{}, p.errorf(name, "expected a type or an operation after '#', found %v", name.describe())
+	}
+	p.position++
+	if p.is("(") {
+		operands, err := p.arguments("the operation " + name.text)
+		return Statement{Type: "operation", OperationType: name.text, Operands: operands}, err
+	}
+	quoted := p.current()
+	if quoted.kind != tokenString {
+		return Statement{}, p.errorf(quoted, "expected a quoted value or '(' after #%v, found %v", name.text, quoted.describe())
+	}
+	p.position++
+	value, err := strconv.Unquote(`"` + quoted.text + `"`)
+	if err != nil {
+		return Statement{}, p.errorf(quoted, "invalid quoted value of #%v: %v", name.text, err)
+	}
+	return Statement{Type: name.text, Value: value}, nil
+}
+
+// arguments parses the arguments of a call between parentheses, context describes the call
+func (p *parser) arguments(context string) ([]Statement, error) {
+	if err := p.expect("(", "before the arguments of "+context); err != nil {
+		return nil, err
+	}
+	arguments := []Statement{}
+	for !p.is(")") {
+		if len(arguments) > 0 {
+			if err := p.expect(",", "between the arguments of "+context); err != nil {
+				return nil, err
+			}
+		}
+		name := ""
+		if length := p.nameLength(); length > 0 && p.tokens[p.position+length].kind == tokenSymbol && p.tokens[p.position+length].text == ":" {
+			var err error
+			if name, err = p.identifier("the name of an argument"); err != nil {
+				return nil, err
+			}
+			p.position++
+		}
+		argument, err := p.expression(0)
+		if err != nil {
+			return nil, err
+		}
+		argument.ParameterName = name
+		arguments = append(arguments, argument)
+	}
+	p.position++
+	return arguments, nil
+}
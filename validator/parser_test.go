@@ -0,0 +1,167 @@
+package validator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseProgram(t *testing.T) {
+	source, err := ioutil.ReadFile("../data/syntax/program.vl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	program, err := ParseProgram(string(source))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectedResult := ReadTestCaseFromJSON("../data/syntax/program.json")
+	if !reflect.DeepEqual(program, expectedResult) {
+		t.Errorf("Unexpected result. Got\n%s\nwant\n%s", FormatProgram(program), FormatProgram(expectedResult))
+	}
+}
+
+func TestParseProgram_RoundTrip(t *testing.T) {
+	// every fixture, in any input format, is printed and parsed back
+	files := []string{}
+	err := filepath.Walk("../data", func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+		return err
+	})
+	if err != nil || len(files) == 0 {
+		t.Fatalf("No test data: %v", err)
+	}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		program, _, err := InputFormatForFile(file).Decode(data)
+		if err != nil {
+			t.Errorf("%v: unexpected error decoding the file: %v", file, err)
+			continue
+		}
+		printed := PrintProgram(program, PrintOptions{AnnotatePaths: true})
+		parsed, err := ParseProgram(printed)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v\n%v", file, err, printed)
+			continue
+		}
+		if !reflect.DeepEqual(parsed, program) {
+			t.Errorf("%v: the parsed program differs. Got\n%s\nwant\n%s", file, FormatProgram(parsed), FormatProgram(program))
+		}
+	}
+}
+
+func TestParseProgram_RawNames(t *testing.T) {
+	// the names which aren't identifiers are written in their raw form
+	program := Program{Module: "my module", Imports: []string{"import"}, Exports: []string{"func"}, Functions: []Function{
+		{Name: "func", Visibility: PublicVisibility, Parameters: []Parameter{{Name: "true"}, {Name: "prix unitaire"}}, Body: Block{Statements: []Statement{
+			{Type: "variable_declaration", Variable: "private"},
+			{Type: "operation", OperationType: "assignment", Operands: []Statement{
+				{Type: "variable", Variable: "private"},
+				{Type: "variable", Variable: "prix unitaire"},
+			}},
+			{Type: "function_call", CalledFunction: "import.export", Arguments: []Statement{
+				{Type: "variable", Variable: "true", ParameterName: "module"},
+			}},
+			{Type: "function_call", CalledFunction: "a.b.c", Arguments: []Statement{}},
+			{Type: "function_call", CalledFunction: "émettre", Arguments: []Statement{}},
+		}}},
+	}}
+
+	expectedSource := `module #"my module";
+import #"import";
+export #"func";
+
+public func #"func"(#"true", #"prix unitaire") {
+    var #"private";
+    #"private" = #"prix unitaire";
+    #"import.export"(#"module": #"true");
+    #"a.b.c"();
+    #"émettre"();
+}
+`
+	source := PrintProgram(program, PrintOptions{})
+	if source != expectedSource {
+		t.Errorf("Unexpected source. Got\n%v\nwant\n%v", source, expectedSource)
+	}
+	parsed, err := ParseProgram(source)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(parsed, program) {
+		t.Errorf("The parsed program differs. Got\n%s\nwant\n%s", FormatProgram(parsed), FormatProgram(program))
+	}
+}
+
+func TestParseProgram_Operations(t *testing.T) {
+	variable := func(name string) Statement { return Statement{Type: "variable", Variable: name} }
+	operation := func(operationType string, operands ...Statement) Statement {
+		return Statement{Type: "operation", OperationType: operationType, Operands: operands}
+	}
+	a, b, c := variable("a"), variable("b"), variable("c")
+
+	testCases := []struct {
+		source   string
+		expected Statement
+	}{
+		{"a + b * c", operation("addition", a, operation("multiplication", b, c))},
+		{"a + b + c", operation("addition", a, b, c)},
+		{"(a + b) + c", operation("addition", operation("addition", a, b), c)},
+		{"a - b - c", operation("subtraction", operation("subtraction", a, b), c)},
+		{"a = b = c", operation("assignment", a, operation("assignment", b, c))},
+		{"-a * b", operation("multiplication", operation("negation", a), b)},
+		{"!!a", operation("not", operation("not", a))},
+		{"a<=b != c>b", operation("not_equal", operation("less_equal", a, b), operation("greater_than", c, b))},
+		{"1e-3 - 0x1P+4", operation("subtraction", Statement{Type: "numerical", Value: "1e-3"}, Statement{Type: "numerical", Value: "0x1P+4"})},
+		{`#numerical"NaN"`, Statement{Type: "numerical", Value: "NaN"}},
+		{`#string"é\""`, Statement{Type: "string", Value: `é"`}},
+		{"#addition(a)", operation("addition", a)},
+		{`f(a, y: "b\n")`, Statement{Type: "function_call", CalledFunction: "f", Arguments: []Statement{a, {Type: "string", Value: `b\n`, ParameterName: "y"}}}},
+	}
+	for _, testCase := range testCases {
+		program, err := ParseProgram("func main() { " + testCase.source + "; }")
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", testCase.source, err)
+			continue
+		}
+		if result := program.Functions[0].Body.Statements[0]; !reflect.DeepEqual(result, testCase.expected) {
+			t.Errorf("%v: unexpected result. Got %+v, want %+v", testCase.source, result, testCase.expected)
+		}
+	}
+}
+
+func TestParseProgram_Errors(t *testing.T) {
+	testCases := []struct {
+		source   string
+		expected string
+	}{
+		{"main() {}", "1:1: expected 'func' at the start of a function, found 'main'"},
+		{"func main(a b) {}", "1:13: expected ',' between the parameters of function main, found 'b'"},
+		{"func main() {\n    x = 1\n}", "3:1: expected ';' at the end of the statement, found '}'"},
+		{"func main() {\n    var x", "2:10: expected ';' after the declaration of x, found the end of the file"},
+		{"func main() {\n    x = (1 + 2;\n}", "2:15: expected ')' to close the parentheses, found ';'"},
+		{"func main() {\n    f(1,);\n}", "2:9: expected an operand, found ')'"},
+		{"func main() {", "1:14: expected '}' at the end of the body of function main, found the end of the file"},
+		{"func main() { x = \"abc; }", "1:19: unterminated string"},
+		{"func main() { x = @; }", "1:19: unexpected character '@'"},
+		{"func main() { #numerical 1; }", "1:26: expected a quoted value or '(' after #numerical, found '1'"},
+		{`func main() { #string"\q"; }`, `1:22: invalid quoted value of #string: invalid syntax`},
+		{"/* func main() {}", "1:1: unterminated comment"},
+		{"module geometry\nfunc main() {}", "2:1: expected ';' after the name of the module, found 'func'"},
+		{"import geometry units;", "1:17: expected ';' at the end of the imports, found 'units'"},
+		{"func main() { geometry.area; }", "1:28: expected '(' after geometry.area, found ';'"},
+		{`func #"\q"() {}`, `1:7: invalid quoted name: invalid syntax`},
+	}
+	for _, testCase := range testCases {
+		_, err := ParseProgram(testCase.source)
+		if err == nil || err.Error() != testCase.expected {
+			t.Errorf("%q: unexpected error. Got %v, want %v", testCase.source, err, testCase.expected)
+		}
+	}
+}
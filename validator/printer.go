@@ -0,0 +1,248 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// -----------------------------------------
+// Pseudo-code printer
+// -----------------------------------------
+/*
+	PrintProgram renders a program as C-like pseudo-code, e.g.
+
+		func main(a, b = 1) {
+		    var x;
+		    x = a + b * 2;
+		    printNumber(add(x, 2), scale: 3);
+		}
+
+	- operations are written using the symbol and the precedence of the operation registry. An operand is put in
+	parentheses when its precedence isn't higher than the precedence of the operation, e.g. a - (b - c).
+	- named arguments are written as "name: value", default values of the parameters as "name = value".
+	- empty statements, i.e. {} in JSON, are written as ";".
+	- the module name, the imports and the exports are declared before the functions, e.g. "module geometry;",
+	"import math, units;" and "export area;". A module exporting no function is written as "export;".
+	- the visibility of a function precedes its declaration, e.g. "private func checked(value) {".
+	- literals and operations which can't be written in the plain syntax, e.g. the numerical value NaN or an
+	operation with an unexpected number of operands, are written in a raw form: #numerical"NaN" and
+	#addition(x), where the value is quoted as a Go string literal.
+	- names which aren't identifiers, see IsIdentifier, e.g. keywords or names holding spaces, are written as a quoted
+	Go string literal following a #, e.g. #"import" or #"total price". A qualified call is written as such when both
+	the module and the function are identifiers.
+	Any program can be written without losing information, and ParseProgram reads the output back.
+*/
+
+// PrintOptions configures PrintProgram
+type PrintOptions struct {
+	// AnnotatePaths ends each line with a comment holding the JSON path of the function or statement
+	AnnotatePaths bool
+}
+
+// printIndent is the indentation of the nested blocks
+const printIndent = "    "
+
+// PrintProgram returns the pseudo-code of the program
+func PrintProgram(program Program, options PrintOptions) string {
+	printer := programPrinter{options: options}
+	printer.header(program)
+	for i, function := range program.Functions {
+		if i > 0 || printer.builder.Len() > 0 {
+			printer.builder.WriteString("\n")
+		}
+		printer.function(function, functionPath(i))
+	}
+	return printer.builder.String()
+}
+
+// programPrinter holds the state of PrintProgram
+type programPrinter struct {
+	options PrintOptions
+	builder strings.Builder
+	depth   int
+}
+
+// header writes the module declarations preceding the functions, e.g. module geometry; import math; export area;
+func (p *programPrinter) header(program Program) {
+	if program.Module != "" {
+		p.line("module "+printName(program.Module)+";", "")
+	}
+	if len(program.Imports) > 0 {
+		p.line("import "+printNames(program.Imports)+";", "")
+	}
+	if program.Exports != nil {
+		p.line(strings.TrimSpace("export "+printNames(program.Exports))+";", "")
+	}
+}
+
+// line writes a line at the current indentation, annotated with the JSON path if requested
+func (p *programPrinter) line(text string, path string) {
+	p.builder.WriteString(strings.Repeat(printIndent, p.depth))
+	p.builder.WriteString(text)
+	if p.options.AnnotatePaths && path != "" {
+		p.builder.WriteString("  // " + path)
+	}
+	p.builder.WriteString("\n")
+}
+
+func (p *programPrinter) function(function Function, path string) {
+	parameters := make([]string, len(function.Parameters))
+	for i, param := range function.Parameters {
+		parameters[i] = printName(param.Name)
+		if param.Default != nil {
+			parameters[i] += " = " + printOperand(*param.Default, 0)
+		}
+	}
+	header := fmt.Sprintf("func %v(%v) {", printName(function.Name), strings.Join(parameters, ", "))
+	if function.Visibility != "" {
+		header = function.Visibility + " " + header
+	}
+	if len(function.Body.Statements) == 0 {
+		p.line(header+"}", path)
+		return
+	}
+	p.line(header, path)
+	p.block(function.Body, path+".body")
+	p.line("}", "")
+}
+
+// block writes the statements of a block, one level deeper than the current indentation
+func (p *programPrinter) block(block Block, path string) {
+	p.depth++
+	for i, statement := range block.Statements {
+		p.statement(statement, elementPath(path, "statements", i))
+	}
+	p.depth--
+}
+
+func (p *programPrinter) statement(statement Statement, path string) {
+	switch statement.Type {
+	case "":
+		p.line(";", path)
+	case "block":
+		if len(statement.Block.Statements) == 0 {
+			p.line("{}", path)
+			return
+		}
+		p.line("{", path)
+		p.block(statement.Block, path+".block")
+		p.line("}", "")
+	case "variable_declaration":
+		p.line("var "+printName(statement.Variable)+";", path)
+	default:
+		p.line(printOperand(statement, 0)+";", path)
+	}
+}
+
+// printOperand returns the pseudo-code of an operand, put in parentheses if it is an operation whose precedence
+// isn't higher than the given precedence of the enclosing operation
+func printOperand(operand Statement, precedence int) string {
+	text := ""
+	switch operand.Type {
+	case "variable":
+		text = printName(operand.Variable)
+	case "numerical":
+		text = operand.Value
+		if !numberLiteral.MatchString(operand.Value) {
+			text = rawLiteral(operand)
+		}
+	case "string":
+		text = `"` + operand.Value + `"`
+		if !isPlainString(operand.Value) {
+			text = rawLiteral(operand)
+		}
+	case "boolean":
+		text = operand.Value
+		if _, err := ParseBoolean(operand.Value); err != nil {
+			text = rawLiteral(operand)
+		}
+	case "function_call":
+		text = printCalledFunction(operand.CalledFunction) + "(" + printArguments(operand.Arguments) + ")"
+	case "operation":
+		spec, known := Operations[operand.OperationType]
+		isUnary := known && spec.MaxOperands == 1 && len(operand.Operands) == 1
+		isInfix := known && spec.MaxOperands != 1 && spec.AcceptsOperandCount(len(operand.Operands)) && len(operand.Operands) >= 2
+		switch {
+		case spec.Symbol != "" && isUnary:
+			text = spec.Symbol + printOperand(operand.Operands[0], spec.Precedence)
+		case spec.Symbol != "" && isInfix:
+			operands := make([]string, len(operand.Operands))
+			for i, nested := range operand.Operands {
+				operands[i] = printOperand(nested, spec.Precedence)
+			}
+			text = strings.Join(operands, " "+spec.Symbol+" ")
+		default:
+			text = "#" + operand.OperationType + "(" + printArguments(operand.Operands) + ")"
+		}
+		if (isUnary || isInfix) && spec.Symbol != "" && spec.Precedence <= precedence {
+			text = "(" + text + ")"
+		}
+	default:
+		text = rawLiteral(operand)
+	}
+	if operand.ParameterName != "" {
+		text = printName(operand.ParameterName) + ": " + text
+	}
+	return text
+}
+
+// printArguments returns the comma separated pseudo-code of the arguments of a call
+func printArguments(arguments []Statement) string {
+	printed := make([]string, len(arguments))
+	for i, arg := range arguments {
+		printed[i] = printOperand(arg, 0)
+	}
+	return strings.Join(printed, ", ")
+}
+
+// printName returns the name if it is an identifier, or its raw form, e.g. #"import"
+func printName(name string) string {
+	if IsIdentifier(name) {
+		return name
+	}
+	return "#" + strconv.Quote(name)
+}
+
+// printNames returns the comma separated names
+func printNames(names []string) string {
+	printed := make([]string, len(names))
+	for i, name := range names {
+		printed[i] = printName(name)
+	}
+	return strings.Join(printed, ", ")
+}
+
+// printCalledFunction returns the name of a called function, e.g. area, geometry.area or #"my-area"
+func printCalledFunction(name string) string {
+	if module, functionName := SplitQualifiedName(name); module != "" && IsIdentifier(module) && IsIdentifier(functionName) {
+		return name
+	}
+	return printName(name)
+}
+
+// rawLiteral returns the raw form of a literal, its type followed by its quoted value
+func rawLiteral(operand Statement) string {
+	return "#" + operand.Type + strconv.Quote(operand.Value)
+}
+
+// numberLiteral matches the numerical values written in the plain syntax: decimal and hexadecimal numbers
+var numberLiteral = regexp.MustCompile(`^([0-9][0-9_]*(\.[0-9_]*)?([eE][+-]?[0-9_]+)?|0[xX][0-9a-fA-F_]*(\.[0-9a-fA-F_]*)?([pP][+-]?[0-9_]+)?)$`)
+
+// isPlainString returns true if a string value can be written between double quotes: its escape sequences are
+// valid, and it holds neither an unescaped double quote nor a control character
+func isPlainString(value string) bool {
+	if _, err := UnescapeString(value); err != nil {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\':
+			i++
+		case value[i] == '"' || value[i] < ' ':
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package validator
+
+import "testing"
+
+func TestPrintProgram(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/valid/function_call_with_default_parameters.json")
+	expectedResult := `func main() {
+    calculateSum(2);
+    calculateSum(2, 10);
+}
+
+func calculateSum(a, b = a) {
+    a + b;
+}
+`
+	if result := PrintProgram(program, PrintOptions{}); result != expectedResult {
+		t.Errorf("Unexpected result. Got\n%v\nwant\n%v", result, expectedResult)
+	}
+}
+
+func TestPrintProgram_AnnotatePaths(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/valid/operations.json")
+	expectedResult := `func myFunction(arg1, arg2) {  // functions[0]
+    {  // functions[0].body.statements[0]
+        var result;  // functions[0].body.statements[0].block.statements[0]
+        result = arg1 + arg2;  // functions[0].body.statements[0].block.statements[1]
+    }
+}
+`
+	if result := PrintProgram(program, PrintOptions{AnnotatePaths: true}); result != expectedResult {
+		t.Errorf("Unexpected result. Got\n%v\nwant\n%v", result, expectedResult)
+	}
+}
+
+func TestPrintOperand(t *testing.T) {
+	variable := func(name string) Statement { return Statement{Type: "variable", Variable: name} }
+	operation := func(operationType string, operands ...Statement) Statement {
+		return Statement{Type: "operation", OperationType: operationType, Operands: operands}
+	}
+	named := func(name string, operand Statement) Statement {
+		operand.ParameterName = name
+		return operand
+	}
+	a, b, c := variable("a"), variable("b"), variable("c")
+
+	testCases := []struct {
+		operand  Statement
+		expected string
+	}{
+		{operation("subtraction", a, operation("subtraction", b, c)), "a - (b - c)"},
+		{operation("subtraction", operation("subtraction", a, b), c), "(a - b) - c"},
+		{operation("multiplication", operation("addition", a, b), c), "(a + b) * c"},
+		{operation("addition", a, operation("multiplication", b, c)), "a + b * c"},
+		{operation("addition", a, b, c), "a + b + c"},
+		{operation("negation", operation("negation", a)), "-(-a)"},
+		{operation("not", operation("and", a, operation("or", b, c))), "!(a && (b || c))"},
+		{operation("assignment", a, operation("assignment", b, c)), "a = (b = c)"},
+		{operation("assignment", a, Statement{Type: "function_call", CalledFunction: "f", Arguments: []Statement{b, named("y", c)}}), "a = f(b, y: c)"},
+		{operation("concatenation", Statement{Type: "string", Value: `say \"hi\"\n`}, a), `"say \"hi\"\n" ++ a`},
+		// raw forms
+		{Statement{Type: "numerical", Value: "NaN"}, `#numerical"NaN"`},
+		{Statement{Type: "numerical", Value: "-1"}, `#numerical"-1"`},
+		{Statement{Type: "numerical", Value: "1.5e-3"}, "1.5e-3"},
+		{Statement{Type: "string", Value: `bad\q`}, `#string"bad\\q"`},
+		{Statement{Type: "boolean", Value: "yes"}, `#boolean"yes"`},
+		{operation("addition", a), "#addition(a)"},
+		{named("x", operation("modulus", a, b)), "x: #modulus(a, b)"},
+	}
+	for _, testCase := range testCases {
+		if result := printOperand(testCase.operand, 0); result != testCase.expected {
+			t.Errorf("Unexpected result. Got %v, want %v", result, testCase.expected)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+)
+
+// -----------------------------------------
+// Rename refactoring
+// -----------------------------------------
+
+// ErrRenameCollision is returned when a rename would reuse a name which is already declared
+var ErrRenameCollision = errors.New("name collision")
+
+// ErrRenameExported is returned when renaming a function which the programs importing the module may call, or one of
+// its parameters
+var ErrRenameExported = errors.New("renaming an exported function")
+
+// RenameFunction returns a copy of the program where the function and every call to it are renamed,
+// including the calls nested in operands, arguments and default values.
+// The new name must be an identifier, see IsIdentifier, which isn't already declared nor the name of a built-in
+// function. The functions exported by a module can't be renamed, since the programs importing it call them by name.
+func RenameFunction(program Program, name string, newName string) (Program, error) {
+	if !IsIdentifier(newName) {
+		return Program{}, fmt.Errorf("the new name: %q is not a valid identifier", newName)
+	}
+	if IsBuiltin(newName) {
+		return Program{}, fmt.Errorf("%w: %v is a built-in function", ErrRenameCollision, newName)
+	}
+	index := -1
+	for i, function := range program.Functions {
+		if function.Name == newName {
+			return Program{}, fmt.Errorf("%w: function: %v is already declared at %v", ErrRenameCollision, newName, functionPath(i))
+		}
+		if function.Name == name {
+			index = i
+		}
+	}
+	if index < 0 {
+		return Program{}, fmt.Errorf("function: %v is not declared", name)
+	}
+	if program.Module != "" && program.IsExported(name) {
+		return Program{}, fmt.Errorf("%w: function: %v is exported by module: %v", ErrRenameExported, name, program.Module)
+	}
+
+	renamed := rewriteProgram(program, func(statement *Statement, path string) {
+		if statement.Type == "function_call" && statement.CalledFunction == name {
+			statement.CalledFunction = newName
+		}
+	})
+	renamed.Functions[index].Name = newName
+	if renamed.Exports != nil {
+		renamed.Exports = append([]string{}, program.Exports...)
+		for i, exported := range renamed.Exports {
+			if exported == name {
+				renamed.Exports[i] = newName
+			}
+		}
+	}
+	return renamed, nil
+}
+
+// FindDeclarations returns the declarations of the variables and parameters with the given name in a function,
+// in program order
+func (program Program) FindDeclarations(functionName string, variable string) []Declaration {
+	declarations := []Declaration{}
+	for i, function := range program.Functions {
+		if function.Name != functionName {
+			continue
+		}
+		for _, declaration := range collectAccesses(function, i).declarations {
+			if declaration.Variable == variable {
+				declarations = append(declarations, declaration)
+			}
+		}
+	}
+	return declarations
+}
+
+// RenameVariable returns a copy of the program where the declared variable or parameter is renamed, along with
+// every variable operand referring to it according to the block scopes. Renaming a parameter also renames the
+// named arguments of the calls binding it.
+// The new name must be an identifier, see IsIdentifier. The parameters of the functions exported by a module can't
+// be renamed, since the programs importing it may bind them by name.
+//
+// The validator resolves the variables of a function in a single scope, so the rename is refused if the new name
+// is declared anywhere in the function, even in another block.
+// It is assumed that the program is valid, i.e. ValidateProgramRec returns true.
+func RenameVariable(program Program, declaration Declaration, newName string) (Program, error) {
+	if !IsIdentifier(newName) {
+		return Program{}, fmt.Errorf("the new name: %q is not a valid identifier", newName)
+	}
+	index := -1
+	for i, function := range program.Functions {
+		if function.Name == declaration.Function {
+			index = i
+		}
+	}
+	if index < 0 {
+		return Program{}, fmt.Errorf("function: %v is not declared", declaration.Function)
+	}
+	function := program.Functions[index]
+	accesses := collectAccesses(function, index)
+
+	id := -1
+	for i, candidate := range accesses.declarations {
+		if candidate.Variable == newName {
+			return Program{}, fmt.Errorf("%w: variable: %v is already declared in function: %v at %v", ErrRenameCollision, newName, function.Name, candidate.Path)
+		}
+		if candidate.Variable == declaration.Variable && candidate.Path == declaration.Path {
+			id = i
+		}
+	}
+	if id < 0 {
+		return Program{}, fmt.Errorf("variable: %v is not declared in function: %v at %v", declaration.Variable, function.Name, declaration.Path)
+	}
+	declaration = accesses.declarations[id]
+	if declaration.Parameter && program.Module != "" && program.IsExported(function.Name) {
+		return Program{}, fmt.Errorf("%w: parameter: %v of function: %v is exported by module: %v", ErrRenameExported, declaration.Variable, function.Name, program.Module)
+	}
+
+	// paths holds the JSON paths of the declaration and of the variable operands referring to it
+	paths := make(set)
+	paths.add(declaration.Path)
+	for _, access := range accesses.accesses {
+		if access.declaration != id {
+			continue
+		}
+		switch access.kind {
+		case accessUse:
+			paths.add(access.path)
+		case accessAssignment:
+			paths.add(elementPath(access.path, "operands", 0))
+		}
+	}
+
+	renamed := rewriteProgram(program, func(statement *Statement, path string) {
+		switch statement.Type {
+		case "variable", "variable_declaration":
+			if _, ok := paths[path]; ok {
+				statement.Variable = newName
+			}
+		case "function_call":
+			if !declaration.Parameter || statement.CalledFunction != function.Name {
+				return
+			}
+			for i := range statement.Arguments {
+				if statement.Arguments[i].ParameterName == declaration.Variable {
+					statement.Arguments[i].ParameterName = newName
+				}
+			}
+		}
+	})
+	if declaration.Parameter {
+		renamed.Functions[index].Parameters[parameterIndex(function, declaration.Variable)].Name = newName
+	}
+	return renamed, nil
+}
@@ -0,0 +1,157 @@
+package validator
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRenameFunction(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/rename/program.json")
+
+	expectedResult := ReadTestCaseFromJSON("../data/rename/program.json")
+	expectedResult.Functions[1].Name = "compute"
+	expectedResult.Functions[0].Body.Statements[1].Operands[1].CalledFunction = "compute"
+
+	result, err := RenameFunction(program, "helper", "compute")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, expectedResult) {
+		t.Errorf("Unexpected result. Got %v, want %v", result, expectedResult)
+	}
+	if program.Functions[1].Name != "helper" || program.Functions[0].Body.Statements[1].Operands[1].CalledFunction != "helper" {
+		t.Errorf("The original program is changed")
+	}
+}
+
+func TestRenameFunction_Collision(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/rename/program.json")
+	if _, err := RenameFunction(program, "helper", "display"); !errors.Is(err, ErrRenameCollision) {
+		t.Errorf("Unexpected error. Got %v, want %v", err, ErrRenameCollision)
+	}
+	if _, err := RenameFunction(program, "missing", "other"); err == nil {
+		t.Errorf("Expected an error when renaming an undeclared function")
+	}
+}
+
+func TestRenameVariable_Parameter(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/rename/program.json")
+
+	// the named argument binding the parameter is renamed as well
+	expectedResult := ReadTestCaseFromJSON("../data/rename/program.json")
+	expectedResult.Functions[1].Parameters[1].Name = "factor"
+	expectedResult.Functions[1].Body.Statements[1].Operands[1].Operands[1].Variable = "factor"
+	expectedResult.Functions[0].Body.Statements[1].Operands[1].Arguments[1].ParameterName = "factor"
+
+	declarations := program.FindDeclarations("helper", "scale")
+	if len(declarations) != 1 {
+		t.Fatalf("Unexpected declarations: %v", declarations)
+	}
+	result, err := RenameVariable(program, declarations[0], "factor")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, expectedResult) {
+		t.Errorf("Unexpected result. Got %v, want %v", result, expectedResult)
+	}
+	if !ValidateProgramRec(result, false) {
+		t.Errorf("The renamed program is not valid")
+	}
+}
+
+func TestRenameVariable_Local(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/rename/program.json")
+
+	expectedResult := ReadTestCaseFromJSON("../data/rename/program.json")
+	statements := expectedResult.Functions[0].Body.Statements
+	statements[0].Variable = "sum"
+	statements[1].Operands[0].Variable = "sum"
+	statements[2].Block.Statements[1].Operands[1].Operands[0].Variable = "sum"
+	statements[3].Arguments[0].Variable = "sum"
+
+	declaration := Declaration{Function: "main", Variable: "total", Path: "functions[0].body.statements[0]"}
+	result, err := RenameVariable(program, declaration, "sum")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, expectedResult) {
+		t.Errorf("Unexpected result. Got %v, want %v", result, expectedResult)
+	}
+}
+
+func TestRenameVariable_Collision(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/rename/program.json")
+
+	// t is declared in a nested block of main
+	declaration := Declaration{Function: "main", Variable: "total", Path: "functions[0].body.statements[0]"}
+	if _, err := RenameVariable(program, declaration, "t"); !errors.Is(err, ErrRenameCollision) {
+		t.Errorf("Unexpected error. Got %v, want %v", err, ErrRenameCollision)
+	}
+
+	declaration.Path = "functions[0].body.statements[1]"
+	if _, err := RenameVariable(program, declaration, "sum"); err == nil {
+		t.Errorf("Expected an error when the declaration doesn't exist")
+	}
+}
+
+func TestRenameVariable_InvalidName(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/rename/program.json")
+	declaration := Declaration{Function: "main", Variable: "total", Path: "functions[0].body.statements[0]"}
+	for _, newName := range []string{"", "func", "a b", "x.y", "1st"} {
+		if _, err := RenameVariable(program, declaration, newName); err == nil {
+			t.Errorf("Expected an error for the new name: %q", newName)
+		}
+	}
+}
+
+func TestRenameVariable_ExportedParameter(t *testing.T) {
+	// the programs importing geometry may bind the parameters of area by name
+	program := ReadTestCaseFromJSON("../data/modules/geometry.json")
+	declaration := program.FindDeclarations("area", "width")[0]
+	if _, err := RenameVariable(program, declaration, "w"); !errors.Is(err, ErrRenameExported) {
+		t.Errorf("Unexpected error. Got %v, want %v", err, ErrRenameExported)
+	}
+
+	// checked is private to the module
+	declaration = program.FindDeclarations("checked", "value")[0]
+	result, err := RenameVariable(program, declaration, "checkedValue")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Functions[2].Parameters[0].Name != "checkedValue" {
+		t.Errorf("Unexpected result. Got %v", result)
+	}
+}
+
+func TestRenameFunction_Exported(t *testing.T) {
+	// the programs importing geometry call area by name
+	program := ReadTestCaseFromJSON("../data/modules/geometry.json")
+	if _, err := RenameFunction(program, "area", "surface"); !errors.Is(err, ErrRenameExported) {
+		t.Errorf("Unexpected error. Got %v, want %v", err, ErrRenameExported)
+	}
+
+	// checked is private to the module
+	result, err := RenameFunction(program, "checked", "validated")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Functions[2].Name != "validated" || result.Functions[0].Body.Statements[0].CalledFunction != "validated" {
+		t.Errorf("Unexpected result. Got %v", result)
+	}
+	if expected := []string{"area", "perimeter"}; !reflect.DeepEqual(result.Exports, expected) {
+		t.Errorf("Unexpected exports. Got %v, want %v", result.Exports, expected)
+	}
+}
+
+func TestRenameFunction_InvalidName(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/rename/program.json")
+	for _, newName := range []string{"", "2x", "my-helper", "units.helper", "func", "private", "héllo"} {
+		if _, err := RenameFunction(program, "helper", newName); err == nil {
+			t.Errorf("Expected an error for the new name: %q", newName)
+		}
+	}
+	if _, err := RenameFunction(program, "helper", "print"); !errors.Is(err, ErrRenameCollision) {
+		t.Errorf("Unexpected error. Got %v, want %v", err, ErrRenameCollision)
+	}
+}
@@ -0,0 +1,362 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// -----------------------------------------
+// TOML decoder
+// -----------------------------------------
+/*
+	decodeTOML reads the subset of TOML needed to write programs by hand:
+		- key/value pairs, whose keys may be bare, quoted or dotted, e.g. body.statements = [].
+		- tables, e.g. [functions.body], and arrays of tables, e.g. [[functions]], which extend the last table of the
+		array, as in [[functions.body.statements]].
+		- arrays, which may span several lines, and inline tables, e.g. { type = "variable", variable = "x" }.
+		- basic strings with their escape sequences, literal strings, and the other values written as is, e.g. 10,
+		1e-3 or true, which are read as strings.
+		- comments starting with #.
+	Multi-line strings are rejected with an error.
+*/
+
+// tomlParser holds the state of decodeTOML
+type tomlParser struct {
+	source  string
+	offset  int
+	line    int
+	column  int
+	root    *sourceNode
+	current *sourceNode // table receiving the key/value pairs
+}
+
+func decodeTOML(data []byte) (Program, SourceMap, error) {
+	root := &sourceNode{kind: nodeMapping, position: Position{Line: 1, Column: 1}}
+	p := tomlParser{source: string(data), line: 1, column: 1, root: root, current: root}
+	for {
+		p.skipSpaces()
+		switch p.peek() {
+		case 0:
+			return decodeDocument(root)
+		case '\n', '\r':
+			p.advance(1)
+			continue
+		case '[':
+			if err := p.header(); err != nil {
+				return Program{}, nil, err
+			}
+		default:
+			if err := p.keyValue(p.current); err != nil {
+				return Program{}, nil, err
+			}
+		}
+		p.skipSpaces()
+		if c := p.peek(); c != 0 && c != '\n' && c != '\r' {
+			return Program{}, nil, p.errorf("expected a new line, found '%c'", c)
+		}
+	}
+}
+
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Line: p.line, Column: p.column, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *tomlParser) position() Position {
+	return Position{Line: p.line, Column: p.column}
+}
+
+// peek returns the current byte, 0 at the end of the document
+func (p *tomlParser) peek() byte {
+	if p.offset >= len(p.source) {
+		return 0
+	}
+	return p.source[p.offset]
+}
+
+// advance moves forward by n bytes, keeping track of the line and the column
+func (p *tomlParser) advance(n int) {
+	for _, c := range []byte(p.source[p.offset : p.offset+n]) {
+		if c == '\n' {
+			p.line++
+			p.column = 1
+		} else {
+			p.column++
+		}
+	}
+	p.offset += n
+}
+
+// skipSpaces skips the spaces and the comment ending the line
+func (p *tomlParser) skipSpaces() {
+	for p.peek() == ' ' || p.peek() == '\t' {
+		p.advance(1)
+	}
+	if p.peek() == '#' {
+		end := strings.IndexByte(p.source[p.offset:], '\n')
+		if end < 0 {
+			end = len(p.source) - p.offset
+		}
+		p.advance(end)
+	}
+}
+
+// skipLines skips the spaces, the comments and the new lines, inside an array
+func (p *tomlParser) skipLines() {
+	for p.skipSpaces(); p.peek() == '\n' || p.peek() == '\r'; p.skipSpaces() {
+		p.advance(1)
+	}
+}
+
+// header parses a table header, e.g. [functions.body], or an array of tables header, e.g. [[functions]]
+func (p *tomlParser) header() error {
+	start := p.position()
+	isArray := strings.HasPrefix(p.source[p.offset:], "[[")
+	if isArray {
+		p.advance(2)
+	} else {
+		p.advance(1)
+	}
+	keys, err := p.key()
+	if err != nil {
+		return err
+	}
+	closing := "]"
+	if isArray {
+		closing = "]]"
+	}
+	if !strings.HasPrefix(p.source[p.offset:], closing) {
+		return p.errorf("expected '%v' at the end of the table header", closing)
+	}
+	p.advance(len(closing))
+
+	table := p.root
+	for _, key := range keys[:len(keys)-1] {
+		if table, err = p.table(table, key, start); err != nil {
+			return err
+		}
+	}
+	last := keys[len(keys)-1]
+	value := table.field(last)
+	if !isArray {
+		if value != nil && value.kind != nodeMapping {
+			return &SyntaxError{Line: start.Line, Column: start.Column, Message: fmt.Sprintf("key %v is already defined as a value", last)}
+		}
+		p.current, err = p.table(table, last, start)
+		return err
+	}
+	if value == nil {
+		value = &sourceNode{kind: nodeSequence, values: []*sourceNode{}, position: start}
+		table.set(last, value)
+	}
+	if value.kind != nodeSequence {
+		return &SyntaxError{Line: start.Line, Column: start.Column, Message: fmt.Sprintf("key %v is already defined as a value", last)}
+	}
+	p.current = &sourceNode{kind: nodeMapping, position: start}
+	value.values = append(value.values, p.current)
+	return nil
+}
+
+// table returns the table of a key, created if it isn't defined yet, or the last table of an array of tables
+func (p *tomlParser) table(parent *sourceNode, key string, position Position) (*sourceNode, error) {
+	value := parent.field(key)
+	switch {
+	case value == nil:
+		value = &sourceNode{kind: nodeMapping, position: position}
+		parent.set(key, value)
+	case value.kind == nodeSequence && len(value.values) > 0 && value.values[len(value.values)-1].kind == nodeMapping:
+		value = value.values[len(value.values)-1]
+	case value.kind != nodeMapping:
+		return nil, &SyntaxError{Line: position.Line, Column: position.Column, Message: fmt.Sprintf("key %v is already defined as a value", key)}
+	}
+	return value, nil
+}
+
+// keyValue parses a key/value pair, added to the table
+func (p *tomlParser) keyValue(table *sourceNode) error {
+	start := p.position()
+	keys, err := p.key()
+	if err != nil {
+		return err
+	}
+	if p.peek() != '=' {
+		return p.errorf("expected '=' after the key %v", strings.Join(keys, "."))
+	}
+	p.advance(1)
+	p.skipSpaces()
+	value, err := p.value()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys[:len(keys)-1] {
+		if table, err = p.table(table, key, start); err != nil {
+			return err
+		}
+	}
+	last := keys[len(keys)-1]
+	if table.field(last) != nil {
+		return &SyntaxError{Line: start.Line, Column: start.Column, Message: fmt.Sprintf("duplicate key: %v", last)}
+	}
+	table.set(last, value)
+	return nil
+}
+
+// key parses a dotted key, followed by spaces
+func (p *tomlParser) key() ([]string, error) {
+	keys := []string{}
+	for {
+		p.skipSpaces()
+		switch c := p.peek(); {
+		case c == '"' || c == '\'':
+			key, err := p.quoted()
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key.value)
+		default:
+			end := p.offset
+			for end < len(p.source) && (isLetter(p.source[end]) || isDigit(p.source[end]) || p.source[end] == '-') {
+				end++
+			}
+			if end == p.offset {
+				if c == 0 {
+					return nil, p.errorf("expected a key, found the end of the document")
+				}
+				return nil, p.errorf("expected a key, found '%c'", c)
+			}
+			keys = append(keys, p.source[p.offset:end])
+			p.advance(end - p.offset)
+		}
+		p.skipSpaces()
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.advance(1)
+	}
+}
+
+// tomlBareValue matches the values written without quotes: booleans, numbers and dates
+var tomlBareValue = regexp.MustCompile(`^(true|false|[+-]?(inf|nan)|[+-]?[0-9][0-9A-Za-z_.:+-]*)$`)
+
+// value parses a string, an array, an inline table or a bare value
+func (p *tomlParser) value() (*sourceNode, error) {
+	start := p.position()
+	switch p.peek() {
+	case '"', '\'':
+		return p.quoted()
+	case '[':
+		p.advance(1)
+		node := &sourceNode{kind: nodeSequence, values: []*sourceNode{}, position: start}
+		for {
+			p.skipLines()
+			if p.peek() == ']' {
+				p.advance(1)
+				return node, nil
+			}
+			if p.peek() == 0 {
+				return nil, &SyntaxError{Line: start.Line, Column: start.Column, Message: "unterminated array, expected ']'"}
+			}
+			element, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			node.values = append(node.values, element)
+			p.skipLines()
+			switch p.peek() {
+			case ',':
+				p.advance(1)
+			case ']':
+			default:
+				return nil, p.errorf("expected ',' or ']' in the array")
+			}
+		}
+	case '{':
+		p.advance(1)
+		node := &sourceNode{kind: nodeMapping, position: start}
+		for p.skipSpaces(); p.peek() != '}'; p.skipSpaces() {
+			if len(node.keys) > 0 {
+				if p.peek() != ',' {
+					return nil, p.errorf("expected ',' or '}' in the inline table")
+				}
+				p.advance(1)
+			}
+			if err := p.keyValue(node); err != nil {
+				return nil, err
+			}
+		}
+		p.advance(1)
+		return node, nil
+	}
+	end := p.offset
+	for end < len(p.source) && !strings.ContainsRune(" \t\r\n,]}#", rune(p.source[end])) {
+		end++
+	}
+	value := p.source[p.offset:end]
+	if !tomlBareValue.MatchString(value) {
+		if value == "" {
+			return nil, p.errorf("expected a value")
+		}
+		return nil, p.errorf("invalid value %v, strings must be quoted", value)
+	}
+	p.advance(end - p.offset)
+	return &sourceNode{kind: nodeScalar, value: value, position: start}, nil
+}
+
+// quoted parses a basic string, with escape sequences, or a literal string
+func (p *tomlParser) quoted() (*sourceNode, error) {
+	start := p.position()
+	quote := p.peek()
+	if strings.HasPrefix(p.source[p.offset:], strings.Repeat(string(quote), 3)) {
+		return nil, p.errorf("multi-line strings are not supported")
+	}
+	p.advance(1)
+	var value strings.Builder
+	for {
+		c := p.peek()
+		switch {
+		case c == 0 || c == '\n':
+			return nil, &SyntaxError{Line: start.Line, Column: start.Column, Message: "unterminated string"}
+		case c == quote:
+			p.advance(1)
+			return &sourceNode{kind: nodeScalar, value: value.String(), position: start}, nil
+		case c == '\\' && quote == '"':
+			length, err := unescapeTOML(p.source[p.offset:], &value)
+			if err != nil {
+				return nil, p.errorf("%v", err)
+			}
+			p.advance(length)
+		default:
+			value.WriteByte(c)
+			p.advance(1)
+		}
+	}
+}
+
+// tomlEscapes are the escape sequences of basic strings, other than \u and \U
+var tomlEscapes = map[byte]string{'b': "\b", 't': "\t", 'n': "\n", 'f': "\f", 'r': "\r", '"': "\"", '\\': "\\"}
+
+// unescapeTOML writes the character of the escape sequence at the start of the text, returning its length
+func unescapeTOML(text string, value *strings.Builder) (int, error) {
+	if len(text) < 2 {
+		return 0, fmt.Errorf("unterminated escape sequence")
+	}
+	if replacement, ok := tomlEscapes[text[1]]; ok {
+		value.WriteString(replacement)
+		return 2, nil
+	}
+	digits := map[byte]int{'u': 4, 'U': 8}[text[1]]
+	if digits == 0 {
+		return 0, fmt.Errorf("invalid escape sequence: \\%c", text[1])
+	}
+	if len(text) < 2+digits {
+		return 0, fmt.Errorf("invalid escape sequence: %v", text)
+	}
+	code, err := strconv.ParseUint(text[2:2+digits], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return 0, fmt.Errorf("invalid escape sequence: %v", text[:2+digits])
+	}
+	value.WriteRune(rune(code))
+	return 2 + digits, nil
+}
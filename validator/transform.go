@@ -0,0 +1,109 @@
+package validator
+
+// -----------------------------------------
+// Program transformations
+// -----------------------------------------
+/*
+	The transformations never change the given program: they copy the functions, blocks and statements they rewrite,
+	so the rewritten program doesn't share any slice with the given one.
+*/
+
+// rewriteFunc rewrites a statement or an operand in place, path is its JSON path in the given program.
+// Its nested blocks, operands and arguments are already rewritten.
+type rewriteFunc func(statement *Statement, path string)
+
+// rewriteProgram returns a copy of the program where rewrite is applied to every statement and operand,
+// including the default values of the parameters
+func rewriteProgram(program Program, rewrite rewriteFunc) Program {
+	rewritten := program
+	rewritten.Functions = make([]Function, len(program.Functions))
+	for i, function := range program.Functions {
+		rewritten.Functions[i] = rewriteFunction(function, functionPath(i), rewrite)
+	}
+	return rewritten
+}
+
+// rewriteFunction returns a copy of the function where rewrite is applied to every statement and operand
+func rewriteFunction(function Function, path string, rewrite rewriteFunc) Function {
+	rewritten := function
+	rewritten.Parameters = make([]Parameter, len(function.Parameters))
+	for i, param := range function.Parameters {
+		rewritten.Parameters[i] = param
+		if param.Default != nil {
+			value := rewriteStatement(*param.Default, elementPath(path, "parameters", i)+".default", rewrite)
+			rewritten.Parameters[i].Default = &value
+		}
+	}
+	rewritten.Body = rewriteBlock(function.Body, path+".body", rewrite)
+	return rewritten
+}
+
+func rewriteBlock(block Block, path string, rewrite rewriteFunc) Block {
+	if block.Statements == nil {
+		return block
+	}
+	rewritten := Block{Statements: make([]Statement, len(block.Statements))}
+	for i, statement := range block.Statements {
+		rewritten.Statements[i] = rewriteStatement(statement, elementPath(path, "statements", i), rewrite)
+	}
+	return rewritten
+}
+
+// rewriteStatement returns a copy of the statement where rewrite is applied to the statement and its nested
+// blocks, operands and arguments
+func rewriteStatement(statement Statement, path string, rewrite rewriteFunc) Statement {
+	rewritten := statement
+	rewritten.Block = rewriteBlock(statement.Block, path+".block", rewrite)
+	if statement.Operands != nil {
+		rewritten.Operands = make([]Statement, len(statement.Operands))
+		for i, operand := range statement.Operands {
+			rewritten.Operands[i] = rewriteStatement(operand, elementPath(path, "operands", i), rewrite)
+		}
+	}
+	if statement.Arguments != nil {
+		rewritten.Arguments = make([]Statement, len(statement.Arguments))
+		for i, arg := range statement.Arguments {
+			rewritten.Arguments[i] = rewriteStatement(arg, elementPath(path, "arguments", i), rewrite)
+		}
+	}
+	rewrite(&rewritten, path)
+	return rewritten
+}
+
+// pathMap maps the JSON paths of the functions and statements of a rewritten program to their path in the program it
+// was rewritten from
+type pathMap map[string]string
+
+// original returns the path of a function or statement in the program the map refers to. A nil map refers to the
+// program itself.
+func (m pathMap) original(path string) string {
+	if m == nil {
+		return path
+	}
+	return m[path]
+}
+
+// then returns the map from the paths of a program rewritten again by next to the paths of the program m refers to.
+// A nil next leaves the program unchanged.
+func (m pathMap) then(next pathMap) pathMap {
+	if next == nil {
+		return m
+	}
+	composed := make(pathMap, len(next))
+	for path, previous := range next {
+		composed[path] = m.original(previous)
+	}
+	return composed
+}
+
+// mapBlockPaths adds the statements of a block, and of its nested blocks, kept as is at newPath to the map
+func mapBlockPaths(block Block, path string, newPath string, paths pathMap) {
+	for i, statement := range block.Statements {
+		statementPath := elementPath(path, "statements", i)
+		newStatementPath := elementPath(newPath, "statements", i)
+		paths[newStatementPath] = statementPath
+		if statement.Type == "block" {
+			mapBlockPaths(statement.Block, statementPath+".block", newStatementPath+".block", paths)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// -----------------------------------------
+// Static types
+// -----------------------------------------
+
+// Type is the static type of a variable or an operand
+type Type int
+
+const (
+	TypeUnknown Type = iota // not inferred (yet), compatible with every type
+	TypeInt
+	TypeFloat
+	TypeBool
+	TypeString
+)
+
+// String returns the name of the type
+func (t Type) String() string {
+	switch t {
+	case TypeInt:
+		return "int"
+	case TypeFloat:
+		return "float"
+	case TypeBool:
+		return "bool"
+	case TypeString:
+		return "string"
+	}
+	return "unknown"
+}
+
+// UnifyTypes combines two types inferred for the same variable or operation:
+// - an unknown type is refined by the other type
+// - int and float are promoted to float
+// - any other pair of different types is a conflict, in which case ok is false
+func UnifyTypes(a Type, b Type) (unified Type, ok bool) {
+	switch {
+	case a == b:
+		return a, true
+	case a == TypeUnknown:
+		return b, true
+	case b == TypeUnknown:
+		return a, true
+	case (a == TypeInt && b == TypeFloat) || (a == TypeFloat && b == TypeInt):
+		return TypeFloat, true
+	}
+	return TypeUnknown, false
+}
+
+// -----------------------------------------
+// Type checking
+// -----------------------------------------
+
+// TypeError reports a conflicting use of types
+type TypeError struct {
+	Function string // name of the function containing the error
+	Path     string // JSON path of the offending node
+	Message  string
+}
+
+func (e TypeError) Error() string {
+	return fmt.Sprintf("%v (function: %v, at %v)", e.Message, e.Function, e.Path)
+}
+
+// TypeCheckResult holds the inferred types and the type errors of a program
+type TypeCheckResult struct {
+	// Variables maps each function to the inferred types of its parameters and variables. The types of the
+	// variables declared with the same name in different blocks are unified, and are unknown if they conflict.
+	Variables map[string]map[string]Type
+	Errors    []TypeError
+}
+
+// typeChecker holds the state of the type inference
+type typeChecker struct {
+	functions map[string]Function
+	// accesses holds the declarations of each function, types the type inferred for each of them, in the same order
+	accesses map[string]functionAccesses
+	types    map[string][]Type
+	// resolved maps the JSON path of each variable operand and assignment of a function to its declaration
+	resolved map[string]map[string]int
+	errors   []TypeError
+	// changed is set when a type is refined, the inference is repeated until it is stable
+	changed bool
+	// errors are only reported in the last pass, once all types are inferred
+	reportErrors bool
+}
+
+// CheckTypes infers the type of each variable from its assignments, and the type of each parameter
+// from the arguments passed at its call sites and from its default value.
+// Each declaration has its own type, resolved using the block scopes as collectAccesses does.
+// Operation operands are checked against the operation registry.
+// Functions don't have a declared return type, so a function call operand has an unknown type.
+//
+// It is assumed that the program is valid, i.e. ValidateProgramRec returns true.
+func CheckTypes(program Program) TypeCheckResult {
+	checker := typeChecker{
+		functions: make(map[string]Function),
+		accesses:  make(map[string]functionAccesses),
+		types:     make(map[string][]Type),
+		resolved:  make(map[string]map[string]int),
+	}
+	for i, function := range program.Functions {
+		accesses := collectAccesses(function, i)
+		checker.functions[function.Name] = function
+		checker.accesses[function.Name] = accesses
+		checker.types[function.Name] = make([]Type, len(accesses.declarations))
+		checker.resolved[function.Name] = make(map[string]int)
+		for _, access := range accesses.accesses {
+			if access.kind != accessParameter {
+				checker.resolved[function.Name][access.path] = access.declaration
+			}
+		}
+	}
+
+	// Types are only ever refined (unknown -> int -> float), so the inference reaches a fixed point.
+	// Parameter types flow between functions through call sites, hence the repetition.
+	checker.changed = true
+	for checker.changed {
+		checker.changed = false
+		checker.checkProgram(program)
+	}
+	checker.reportErrors = true
+	checker.checkProgram(program)
+
+	return TypeCheckResult{Variables: checker.variables(), Errors: checker.errors}
+}
+
+// variables returns the inferred types keyed by function and variable name
+func (c *typeChecker) variables() map[string]map[string]Type {
+	variables := make(map[string]map[string]Type)
+	for functionName, accesses := range c.accesses {
+		variables[functionName] = make(map[string]Type)
+		for id, declaration := range accesses.declarations {
+			t := c.types[functionName][id]
+			if previous, declared := variables[functionName][declaration.Variable]; declared {
+				if unified, ok := UnifyTypes(previous, t); ok {
+					t = unified
+				} else {
+					t = TypeUnknown
+				}
+			}
+			variables[functionName][declaration.Variable] = t
+		}
+	}
+	return variables
+}
+
+func (c *typeChecker) checkProgram(program Program) {
+	for i, function := range program.Functions {
+		path := functionPath(i)
+		for j, param := range function.Parameters {
+			if param.Default != nil {
+				defaultPath := elementPath(path, "parameters", j) + ".default"
+				defaultType := c.inferOperand(*param.Default, function.Name, defaultPath)
+				c.refine(function.Name, c.parameter(function.Name, param.Name), defaultType, defaultPath)
+			}
+		}
+		c.checkBlock(function.Body, function.Name, path+".body")
+	}
+}
+
+func (c *typeChecker) checkBlock(block Block, functionName string, path string) {
+	for i, statement := range block.Statements {
+		c.checkStatement(statement, functionName, elementPath(path, "statements", i))
+	}
+}
+
+func (c *typeChecker) checkStatement(statement Statement, functionName string, path string) {
+	switch statement.Type {
+	case "block":
+		c.checkBlock(statement.Block, functionName, path+".block")
+	case "operation", "function_call":
+		c.inferOperand(statement, functionName, path)
+	}
+}
+
+// declaration returns the declaration of the variable operand or of the variable assigned at the given path, or -1
+// if the variable isn't declared
+func (c *typeChecker) declaration(functionName string, path string) int {
+	if id, resolved := c.resolved[functionName][path]; resolved {
+		return id
+	}
+	return -1
+}
+
+// parameter returns the declaration of the parameter of a function, or -1 if there is none
+func (c *typeChecker) parameter(functionName string, name string) int {
+	for id, declaration := range c.accesses[functionName].declarations {
+		if declaration.Parameter && declaration.Variable == name {
+			return id
+		}
+	}
+	return -1
+}
+
+// typeOf returns the inferred type of a declaration, unknown for an undeclared variable
+func (c *typeChecker) typeOf(functionName string, id int) Type {
+	if id < 0 {
+		return TypeUnknown
+	}
+	return c.types[functionName][id]
+}
+
+// inferOperand returns the type of an operand, checking nested operations and function calls on the way
+func (c *typeChecker) inferOperand(operand Statement, functionName string, path string) Type {
+	switch operand.Type {
+	case "numerical":
+		return numericalType(operand.Value)
+	case "string":
+		return TypeString
+	case "boolean":
+		return TypeBool
+	case "variable":
+		return c.typeOf(functionName, c.declaration(functionName, path))
+	case "function_call":
+		c.inferCall(operand, functionName, path)
+	case "operation":
+		return c.inferOperation(operand, functionName, path)
+	}
+	return TypeUnknown
+}
+
+// numericalType returns int for integer literals and float for any other number
+func numericalType(value string) Type {
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return TypeInt
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return TypeFloat
+	}
+	return TypeUnknown
+}
+
+// inferCall refines the types of the called function's parameters using the types of the arguments
+func (c *typeChecker) inferCall(call Statement, functionName string, path string) {
+	argumentTypes := make([]Type, len(call.Arguments))
+	for i, arg := range call.Arguments {
+		argumentTypes[i] = c.inferOperand(arg, functionName, elementPath(path, "arguments", i))
+	}
+
+	callee, declared := c.functions[call.CalledFunction]
+	if !declared {
+		return
+	}
+	for i, arg := range call.Arguments {
+		index := i
+		if arg.ParameterName != "" {
+			index = parameterIndex(callee, arg.ParameterName)
+		}
+		if index < 0 || index >= len(callee.Parameters) {
+			continue
+		}
+		paramName := callee.Parameters[index].Name
+		param := c.parameter(callee.Name, paramName)
+		previous := c.typeOf(callee.Name, param)
+		if _, ok := UnifyTypes(previous, argumentTypes[i]); !ok {
+			c.report(functionName, elementPath(path, "arguments", i),
+				"argument of type %v passed to parameter: %v of function: %v which is %v", argumentTypes[i], paramName, callee.Name, previous)
+			continue
+		}
+		c.refine(callee.Name, param, argumentTypes[i], path)
+	}
+}
+
+// inferOperation checks the operands of an operation against the operation registry and returns the result type
+func (c *typeChecker) inferOperation(operation Statement, functionName string, path string) Type {
+	operandTypes := make([]Type, len(operation.Operands))
+	for i, operand := range operation.Operands {
+		operandTypes[i] = c.inferOperand(operand, functionName, elementPath(path, "operands", i))
+	}
+
+	spec, known := Operations[operation.OperationType]
+	if !known {
+		c.report(functionName, path, "unknown operation type: %v", operation.OperationType)
+		return TypeUnknown
+	}
+	if !spec.AcceptsOperandCount(len(operation.Operands)) {
+		c.report(functionName, path, "operation: %v doesn't accept %v operands", spec.Name, len(operation.Operands))
+		return TypeUnknown
+	}
+
+	if spec.Name == "assignment" {
+		assigned := c.declaration(functionName, path)
+		c.refine(functionName, assigned, operandTypes[1], path)
+		return c.typeOf(functionName, assigned)
+	}
+
+	for i, operandType := range operandTypes {
+		if !spec.Operands.Accepts(operandType) {
+			c.report(functionName, elementPath(path, "operands", i),
+				"operand of operation: %v must be %v but is %v", spec.Name, spec.Operands, operandType)
+			return spec.Result
+		}
+	}
+	// the operands of an operation are combined the same way assignments to a variable are
+	result := TypeUnknown
+	for i, operandType := range operandTypes {
+		unified, ok := UnifyTypes(result, operandType)
+		if !ok {
+			c.report(functionName, elementPath(path, "operands", i),
+				"operands of operation: %v have conflicting types %v and %v", spec.Name, result, operandType)
+			return spec.Result
+		}
+		result = unified
+	}
+	if spec.Result != TypeUnknown {
+		return spec.Result
+	}
+	return result
+}
+
+// refine unifies the type of a declaration with a newly inferred type, reporting conflicts
+func (c *typeChecker) refine(functionName string, id int, t Type, path string) {
+	if id < 0 {
+		return
+	}
+	previous := c.types[functionName][id]
+	unified, ok := UnifyTypes(previous, t)
+	if !ok {
+		variable := c.accesses[functionName].declarations[id].Variable
+		c.report(functionName, path, "variable: %v of type %v is assigned a value of type %v", variable, previous, t)
+		return
+	}
+	if unified != previous {
+		c.types[functionName][id] = unified
+		c.changed = true
+	}
+}
+
+func (c *typeChecker) report(functionName string, path string, format string, args ...interface{}) {
+	if !c.reportErrors {
+		return
+	}
+	c.errors = append(c.errors, TypeError{Function: functionName, Path: path, Message: fmt.Sprintf(format, args...)})
+}
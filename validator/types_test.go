@@ -0,0 +1,115 @@
+package validator
+
+import (
+	"reflect"
+	"testing"
+)
+
+// checkTypesTestCase a helper function to type check a test case
+func checkTypesTestCase(t *testing.T, filepath string, expectedTypes map[string]map[string]Type, expectedErrors int) {
+	// Define the input program
+	program := ReadTestCaseFromJSON(filepath)
+
+	// Call the function
+	result := CheckTypes(program)
+
+	// Compare the result with the expected output
+	if expectedTypes != nil && !reflect.DeepEqual(result.Variables, expectedTypes) {
+		t.Errorf("Unexpected types. Got %v, want %v", result.Variables, expectedTypes)
+	}
+	if len(result.Errors) != expectedErrors {
+		t.Errorf("Unexpected type errors. Got %v, want %v errors", result.Errors, expectedErrors)
+	}
+}
+
+func TestUnifyTypes(t *testing.T) {
+	testCases := []struct {
+		a, b     Type
+		expected Type
+		ok       bool
+	}{
+		{TypeUnknown, TypeInt, TypeInt, true},
+		{TypeString, TypeUnknown, TypeString, true},
+		{TypeInt, TypeFloat, TypeFloat, true},
+		{TypeBool, TypeBool, TypeBool, true},
+		{TypeInt, TypeBool, TypeUnknown, false},
+		{TypeString, TypeFloat, TypeUnknown, false},
+	}
+	for _, testCase := range testCases {
+		result, ok := UnifyTypes(testCase.a, testCase.b)
+		if result != testCase.expected || ok != testCase.ok {
+			t.Errorf("UnifyTypes(%v, %v) = %v, %v, want %v, %v", testCase.a, testCase.b, result, ok, testCase.expected, testCase.ok)
+		}
+	}
+}
+
+func TestCheckTypes_WellTyped(t *testing.T) {
+	expectedTypes := map[string]map[string]Type{
+		"main":  {"x": TypeInt, "ratio": TypeFloat, "small": TypeBool},
+		"scale": {"value": TypeInt, "factor": TypeInt, "result": TypeInt},
+	}
+	checkTypesTestCase(t, "../data/types/well_typed.json", expectedTypes, 0)
+}
+
+func TestCheckTypes_ConflictingAssignment(t *testing.T) {
+	expectedTypes := map[string]map[string]Type{
+		"main": {"x": TypeInt},
+	}
+	checkTypesTestCase(t, "../data/types/conflicting_assignment.json", expectedTypes, 1)
+}
+
+func TestCheckTypes_OperandTypeMismatch(t *testing.T) {
+	checkTypesTestCase(t, "../data/types/operand_type_mismatch.json", nil, 1)
+}
+
+func TestCheckTypes_ConflictingCallSites(t *testing.T) {
+	expectedTypes := map[string]map[string]Type{
+		"main":  {"flag": TypeBool},
+		"scale": {"value": TypeInt, "result": TypeInt},
+	}
+	checkTypesTestCase(t, "../data/types/conflicting_call_sites.json", expectedTypes, 1)
+}
+
+func TestCheckTypes_ValidPrograms(t *testing.T) {
+	// the programs used to test the validation only use numbers, so they are well typed
+	program := ReadTestCaseFromJSON("../data/valid/function_call_operand.json")
+	result := CheckTypes(program)
+	if len(result.Errors) != 0 {
+		t.Errorf("Unexpected type errors: %v", result.Errors)
+	}
+}
+
+func TestCheckTypes_StringAndBooleanLiterals(t *testing.T) {
+	expectedTypes := map[string]map[string]Type{
+		"main":    {"done": TypeBool, "message": TypeString},
+		"display": {"message": TypeString},
+	}
+	checkTypesTestCase(t, "../data/valid/string_and_boolean_literals.json", expectedTypes, 0)
+}
+
+func TestCheckTypes_SameNameInSiblingBlocks(t *testing.T) {
+	// each declaration of x has its own type, resolved using the block scopes
+	assign := func(value Statement) Statement {
+		return Statement{Type: "operation", OperationType: "assignment", Operands: []Statement{{Type: "variable", Variable: "x"}, value}}
+	}
+	program := Program{Functions: []Function{{Name: "main", Parameters: []Parameter{}, Body: Block{Statements: []Statement{
+		{Type: "block", Block: Block{Statements: []Statement{
+			{Type: "variable_declaration", Variable: "x"},
+			assign(Statement{Type: "numerical", Value: "1"}),
+		}}},
+		{Type: "block", Block: Block{Statements: []Statement{
+			{Type: "variable_declaration", Variable: "x"},
+			assign(Statement{Type: "string", Value: "one"}),
+			{Type: "operation", OperationType: "concatenation", Operands: []Statement{{Type: "variable", Variable: "x"}, {Type: "string", Value: "!"}}},
+		}}},
+	}}}}}
+
+	result := CheckTypes(program)
+	if len(result.Errors) != 0 {
+		t.Errorf("Unexpected type errors: %v", result.Errors)
+	}
+	// the conflicting types of the declarations sharing a name are unknown
+	if expected := map[string]map[string]Type{"main": {"x": TypeUnknown}}; !reflect.DeepEqual(result.Variables, expected) {
+		t.Errorf("Unexpected types. Got %v, want %v", result.Variables, expected)
+	}
+}
@@ -17,7 +17,9 @@ Tool features implementd in this script:
 ----------------------
 The tool expects a file specifying a program for the previously defined representation, and offers three different operations:
 	1. Verify that a program is valid. The conditions for a program to be valid are:
-		a. A function call must call a function that is declared in the same file.
+		a. A function call must call a function that is declared in the same file, a built-in function of the
+		   interpreter, e.g. print, see DefaultBuiltins,
+		   or exported by an imported module, e.g. geometry.area, see modules.go.
 		b. A variable can only be used in operations if it has been declared in a previous statement of the same block, or in case it has been declared in
 		one of the previous statements of a surrounding block.
 
@@ -37,14 +39,24 @@ The tool expects a file specifying a program for the previously defined represen
 ----------------------
 Assumptions:
 ----------------------
-	- variables are dynamically typed
+	- variables are dynamically typed, static types can be inferred using CheckTypes (see types.go)
 	- operands in an operation or arguments in a function call can be:
 		- numerical
+		- string: the value may contain the escape sequences \\, \", \n, \t, \r and \uXXXX
+		- boolean: the value is either true or false
 		- variable
 		- function call
 		- operation
 	- variables can't be declared twice
 	- function's parameters are considered as declaration for variable and they are already assigned
+	- a parameter may have a default value, which is used when a call omits the argument:
+		- a call passes between the number of required parameters and the total number of parameters
+		- a default value may only use the parameters preceding it
+		- a parameter without a default value can't follow a parameter with a default value
+	- arguments in a function call are positional, unless they carry a "parameter_name":
+		- named arguments are bound to the parameter with the same name
+		- a positional argument can't follow a named argument
+		- a parameter can't be bound more than once
 	- in Assignment operation, the assigned variable is the first variable in the operations list
 	- in Assignment operation, the parser correctly parse the input into exactly two operands where the first one is the assigned variable. Otherwise throws error
 	- When operating in function dependancies or unused variables modes, it is assumed that the program is already valid:
@@ -54,8 +66,12 @@ Assumptions:
 package validator
 
 import (
+	"encoding/json"
 	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 )
 
 // -----------------------------------------
@@ -64,6 +80,10 @@ import (
 
 // Program represents the top-level structure of the program.
 type Program struct {
+	// A program may be a module, whose exported functions can be called from the programs importing it, see modules.go
+	Module  string   `json:"module,omitempty"`  // name of the module, empty if the program can't be imported
+	Imports []string `json:"imports,omitempty"` // modules whose exported functions can be called, e.g. geometry.area
+	Exports []string `json:"exports,omitempty"` // functions callable from other modules, nil if they all are
 	// A program contains one or more function declarations.
 	Functions []Function `json:"functions"` // List of function declarations
 }
@@ -74,11 +94,59 @@ type Function struct {
 		A function declaration contains a block specifying the function body.
 		Extra added info:
 			- function identifier "name"
+			- function visibility, see modules.go
 			- function Parameters
 	*/
-	Name       string   `json:"name"`       // Name of the function
-	Parameters []string `json:"parameters"` // List of function arguments
-	Body       Block    `json:"body"`       // Function body
+	Name       string      `json:"name"`                 // Name of the function
+	Visibility string      `json:"visibility,omitempty"` // public, private, or empty to follow the exports of the module
+	Parameters []Parameter `json:"parameters"`           // List of function arguments
+	Body       Block       `json:"body"`                 // Function body
+}
+
+// Parameter represents a function parameter.
+// In JSON a parameter is either a plain string holding its name, or an object
+// with a name and an optional default value used when a call omits the argument.
+type Parameter struct {
+	Name    string     `json:"name"`              // Name of the parameter
+	Default *Statement `json:"default,omitempty"` // Optional default value (an operand)
+}
+
+// UnmarshalJSON accepts both the plain string form and the object form of a parameter
+func (p *Parameter) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*p = Parameter{Name: name}
+		return nil
+	}
+	// use an alias type to avoid calling UnmarshalJSON recursively
+	type parameterObject Parameter
+	var obj parameterObject
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	*p = Parameter(obj)
+	return nil
+}
+
+// MarshalJSON writes parameters without a default value in the plain string form
+func (p Parameter) MarshalJSON() ([]byte, error) {
+	if p.Default == nil {
+		return json.Marshal(p.Name)
+	}
+	type parameterObject Parameter
+	return json.Marshal(parameterObject(p))
+}
+
+// RequiredArity returns the number of parameters that have no default value,
+// i.e. the minimum number of arguments a call must pass.
+func (f Function) RequiredArity() int {
+	required := 0
+	for _, param := range f.Parameters {
+		if param.Default == nil {
+			required++
+		}
+	}
+	return required
 }
 
 // Block represents a block of statements.
@@ -90,13 +158,14 @@ type Block struct {
 // Statement represents an individual statement.
 type Statement struct {
 	Type           string      `json:"type"`                      // Type of statement (block, variable_declaration, operation, function_call)
-	Value          string      `json:"value,omitempty"`           // declared variable
+	Value          string      `json:"value,omitempty"`           // value of a numerical, string or boolean literal
 	Variable       string      `json:"variable,omitempty"`        // declared variable
 	Block          Block       `json:"block,omitempty"`           // Nested block
 	OperationType  string      `json:"operation_type,omitempty"`  // Type of operation (e.g., addition, multiplication)
-	Operands       []Statement `json:"Operands,omitempty"`        // List of variable used as Operands
+	Operands       []Statement `json:"operands,omitempty"`        // List of variable used as Operands
 	CalledFunction string      `json:"called_function,omitempty"` // function call
 	Arguments      []Statement `json:"arguments,omitempty"`       // List of function call arguments
+	ParameterName  string      `json:"parameter_name,omitempty"`  // Parameter bound by a named (keyword) argument
 }
 
 // --------------------------
@@ -117,28 +186,226 @@ func (s set) append(other set) {
 	}
 }
 
+// --------------------------
+// JSON paths
+// --------------------------
+// Diagnostics refer to a node of the program using its path in the JSON representation,
+// e.g. functions[0].body.statements[2].operands[1]
+
+// functionPath returns the JSON path of the function at the given index
+func functionPath(index int) string {
+	return fmt.Sprintf("functions[%d]", index)
+}
+
+// elementPath returns the JSON path of the element at the given index of a list field
+func elementPath(path string, field string, index int) string {
+	return fmt.Sprintf("%s.%s[%d]", path, field, index)
+}
+
+// blockPath returns the path of the block holding a statement, or an empty string if the statement isn't in a block
+func blockPath(statementPath string) string {
+	end := strings.LastIndex(statementPath, ".statements[")
+	if end < 0 {
+		return ""
+	}
+	return statementPath[:end]
+}
+
+// lessPath orders JSON paths comparing the indices numerically, so that statements[2] comes before statements[10]
+func lessPath(a string, b string) bool {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			startA, startB := i, j
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+			indexA, _ := strconv.Atoi(a[startA:i])
+			indexB, _ := strconv.Atoi(b[startB:j])
+			if indexA != indexB {
+				return indexA < indexB
+			}
+			continue
+		}
+		if a[i] != b[j] {
+			return a[i] < b[j]
+		}
+		i++
+		j++
+	}
+	return len(a)-i < len(b)-j
+}
+
+// --------------------------
+// Literals
+// --------------------------
+
+// UnescapeString returns the content of a string literal after replacing its escape sequences.
+// The supported escape sequences are \\, \", \n, \t, \r and \uXXXX.
+func UnescapeString(value string) (string, error) {
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' {
+			builder.WriteByte(value[i])
+			continue
+		}
+		if i+1 == len(value) {
+			return "", fmt.Errorf("unterminated escape sequence at the end of the string")
+		}
+		i++
+		switch value[i] {
+		case '\\':
+			builder.WriteByte('\\')
+		case '"':
+			builder.WriteByte('"')
+		case 'n':
+			builder.WriteByte('\n')
+		case 't':
+			builder.WriteByte('\t')
+		case 'r':
+			builder.WriteByte('\r')
+		case 'u':
+			if i+4 >= len(value) {
+				return "", fmt.Errorf("incomplete escape sequence \\%v", value[i:])
+			}
+			code, err := strconv.ParseUint(value[i+1:i+5], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("invalid escape sequence \\%v", value[i:i+5])
+			}
+			builder.WriteRune(rune(code))
+			i += 4
+		default:
+			return "", fmt.Errorf("invalid escape sequence \\%c", value[i])
+		}
+	}
+	return builder.String(), nil
+}
+
+// ParseBoolean returns the value of a boolean literal, which is either "true" or "false"
+func ParseBoolean(value string) (bool, error) {
+	switch value {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean value: %v", value)
+}
+
 // -----------------------------------------
 // Validate a program
 // -----------------------------------------
 
+// BindArguments matches the arguments of a call to the parameters of the called function.
+// Positional arguments are bound in order, and named arguments are bound by their ParameterName.
+// The returned slice holds the operand bound to each parameter, which is the parameter's default value when the argument is omitted.
+// An error is returned for:
+// - a positional argument following a named argument
+// - too many positional arguments
+// - a named argument referring to an unknown parameter
+// - a parameter bound more than once
+// - an omitted parameter without a default value
+func BindArguments(function Function, arguments []Statement) ([]Statement, error) {
+	indices, err := BindArgumentIndices(function, arguments)
+	if err != nil {
+		return nil, err
+	}
+	boundOperands := make([]Statement, len(function.Parameters))
+	for i, index := range indices {
+		if index < 0 {
+			boundOperands[i] = *function.Parameters[i].Default
+		} else {
+			boundOperands[i] = arguments[index]
+		}
+	}
+	return boundOperands, nil
+}
+
+// BindArgumentIndices works like BindArguments, but returns the index of the argument bound to each parameter,
+// or -1 when the parameter's default value is used.
+func BindArgumentIndices(function Function, arguments []Statement) ([]int, error) {
+	indices := make([]int, len(function.Parameters))
+	for i := range indices {
+		indices[i] = -1
+	}
+	seenNamed := false
+	for i, arg := range arguments {
+		index := i
+		if arg.ParameterName == "" {
+			if seenNamed {
+				return nil, fmt.Errorf("positional argument %v follows a named argument", i+1)
+			}
+			if i >= len(function.Parameters) {
+				return nil, fmt.Errorf("too many arguments, expects at most %v but received %v", len(function.Parameters), len(arguments))
+			}
+		} else {
+			seenNamed = true
+			index = parameterIndex(function, arg.ParameterName)
+			if index < 0 {
+				return nil, fmt.Errorf("unknown parameter: %v", arg.ParameterName)
+			}
+			if indices[index] >= 0 {
+				return nil, fmt.Errorf("parameter: %v is bound more than once", arg.ParameterName)
+			}
+		}
+		indices[index] = i
+	}
+
+	for i, param := range function.Parameters {
+		if indices[i] < 0 && param.Default == nil {
+			return nil, fmt.Errorf("missing argument for parameter: %v", param.Name)
+		}
+	}
+	return indices, nil
+}
+
+// parameterIndex returns the position of the parameter with the given name, or -1 if there is none
+func parameterIndex(function Function, name string) int {
+	for i, param := range function.Parameters {
+		if param.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
 // IsValidFunctionCall validates a function call by checking the following conditions:
 // - The function is already declared.
+// - The arguments can be bound to the parameters (see BindArguments), so the number of arguments
+// is between the number of required parameters and the total number of parameters.
 // - All arguments are valid operands
 // - All variable arguments are both declared and assigned.
-func IsValidFunctionCall(functionName string, arguments []Statement, declaredFunctionsMap map[string]int, assignedVarMap map[string]bool, verbose bool) bool {
-	// in the funcMap, set val to function arity and check on the length of arguments
+func IsValidFunctionCall(functionName string, arguments []Statement, declaredFunctionsMap map[string]Function, assignedVarMap map[string]bool, policy NumericPolicy, verbose bool) bool {
 	// ensure function is already declared
-	arity, declared := declaredFunctionsMap[functionName]
-
+	function, declared := declaredFunctionsMap[functionName]
+
+	if !declared && IsBuiltin(functionName) {
+		// the built-in functions take any number of positional arguments
+		for _, arg := range arguments {
+			if arg.ParameterName != "" {
+				if verbose {
+					fmt.Printf("Invalid function call due to passing a named argument: %v to built-in function: %v\n", arg.ParameterName, functionName)
+				}
+				return false
+			}
+			if !IsValidOperand(arg, false, declaredFunctionsMap, assignedVarMap, policy, verbose) {
+				return false
+			}
+		}
+		return true
+	}
 	if !declared {
 		if verbose {
 			fmt.Println("Invalid function call due to calling undefined function: ", functionName)
 		}
 		return false
 	}
-	if arity != len(arguments) {
+	if _, err := BindArguments(function, arguments); err != nil {
 		if verbose {
-			fmt.Println("Invalid function call due to calling with different arity. Function: ", functionName)
+			fmt.Printf("Invalid function call due to %v. Function: %v\n", err, functionName)
 		}
 		return false
 	}
@@ -147,7 +414,7 @@ func IsValidFunctionCall(functionName string, arguments []Statement, declaredFun
 	for _, arg := range arguments {
 		// note: all argumets should be both declared and assigned in the assignedVarMap.
 		// Thus second param to function call is set to false "not an assigned var"
-		if !IsValidOperand(arg, false, declaredFunctionsMap, assignedVarMap, verbose) {
+		if !IsValidOperand(arg, false, declaredFunctionsMap, assignedVarMap, policy, verbose) {
 			return false
 		}
 	}
@@ -156,11 +423,13 @@ func IsValidFunctionCall(functionName string, arguments []Statement, declaredFun
 
 // isValidOperand validates an operand by checking the following conditions:
 // - If isAssignedVar is true, the operand must be of type "variable" for assignment.
-// - For numerical operands, it checks if the value can be converted to a float, integers are accepted as well.
+// - For numerical operands, it checks if the value is accepted by the numeric policy, see NumericPolicy.
+// - For string operands, it checks that the value only contains valid escape sequences.
+// - For boolean operands, it checks that the value is either "true" or "false".
 // - For variable operands, it checks if the variable is declared and assigned (unless isAssignedVar is true).
 // - For function call operands and operation operands, it recursively checks the validity of the statement using isValidStatement.
 //
-func IsValidOperand(operand Statement, isAssignedVar bool, declaredFunctionsMap map[string]int, assignedVarMap map[string]bool, verbose bool) bool {
+func IsValidOperand(operand Statement, isAssignedVar bool, declaredFunctionsMap map[string]Function, assignedVarMap map[string]bool, policy NumericPolicy, verbose bool) bool {
 	// early handle special common case: in assignment, first operand must be a var
 	if isAssignedVar && operand.Type != "variable" {
 		if verbose {
@@ -171,13 +440,27 @@ func IsValidOperand(operand Statement, isAssignedVar bool, declaredFunctionsMap
 
 	switch operand.Type {
 	case "numerical":
-		if _, err := strconv.ParseFloat(operand.Value, 64); err != nil {
+		if err := policy.CheckNumericalLiteral(operand.Value); err != nil {
 			if verbose {
-				fmt.Printf("Invalid operand, expected numerical type and value %v couldn't be converted\n", operand.Value)
+				fmt.Printf("Invalid operand, expected numerical type and value %v is rejected by the %v numeric policy: %v\n", operand.Value, policy.Name, err)
 				fmt.Println(operand)
 			}
 			return false
 		}
+	case "string":
+		if _, err := UnescapeString(operand.Value); err != nil {
+			if verbose {
+				fmt.Printf("Invalid operand, string value %v is invalid: %v\n", operand.Value, err)
+			}
+			return false
+		}
+	case "boolean":
+		if _, err := ParseBoolean(operand.Value); err != nil {
+			if verbose {
+				fmt.Printf("Invalid operand, expected boolean type and value %v is neither true nor false\n", operand.Value)
+			}
+			return false
+		}
 	case "variable":
 		// a variable should be declared to be used in an operation.
 		// The assignment is checked in the calling function to check on the opertaion type, if it is an assignment operation or sth else
@@ -198,7 +481,7 @@ func IsValidOperand(operand Statement, isAssignedVar bool, declaredFunctionsMap
 		fallthrough
 	case "operation":
 		// function calls and operations are statements
-		if !IsValidStatement(operand, declaredFunctionsMap, assignedVarMap, verbose) {
+		if !IsValidStatement(operand, declaredFunctionsMap, assignedVarMap, policy, verbose) {
 			if verbose {
 				fmt.Println("Invalid operand, due to invalid statement:", operand)
 			}
@@ -216,10 +499,10 @@ func IsValidOperand(operand Statement, isAssignedVar bool, declaredFunctionsMap
 
 // IsValidStatement checks the validity of a statement by calling the corresponding validating function
 // based on the statement type
-func IsValidStatement(statement Statement, declaredFunctionsMap map[string]int, assignedVarMap map[string]bool, verbose bool) bool {
+func IsValidStatement(statement Statement, declaredFunctionsMap map[string]Function, assignedVarMap map[string]bool, policy NumericPolicy, verbose bool) bool {
 	switch statement.Type {
 	case "block":
-		if !ValidateBlock(statement.Block, declaredFunctionsMap, assignedVarMap, verbose) {
+		if !ValidateBlock(statement.Block, declaredFunctionsMap, assignedVarMap, policy, verbose) {
 			if verbose {
 				fmt.Println("Invalid block: ", statement.Block)
 			}
@@ -239,7 +522,7 @@ func IsValidStatement(statement Statement, declaredFunctionsMap map[string]int,
 		for i, operand := range statement.Operands {
 			// in assignment operation, the assigned variable is the first
 			isAssignedVar := (i == 0 && statement.OperationType == "assignment")
-			if !IsValidOperand(operand, isAssignedVar, declaredFunctionsMap, assignedVarMap, verbose) {
+			if !IsValidOperand(operand, isAssignedVar, declaredFunctionsMap, assignedVarMap, policy, verbose) {
 				return false
 			}
 		}
@@ -248,135 +531,249 @@ func IsValidStatement(statement Statement, declaredFunctionsMap map[string]int,
 			assignedVarMap[statement.Operands[0].Variable] = true
 		}
 	case "function_call":
-		if !IsValidFunctionCall(statement.CalledFunction, statement.Arguments, declaredFunctionsMap, assignedVarMap, verbose) {
+		if !IsValidFunctionCall(statement.CalledFunction, statement.Arguments, declaredFunctionsMap, assignedVarMap, policy, verbose) {
 			return false
 		}
 	}
 	return true
 }
 
-func ValidateBlock(block Block, declaredFunctionsMap map[string]int, assignedVarMap map[string]bool, verbose bool) bool {
+func ValidateBlock(block Block, declaredFunctionsMap map[string]Function, assignedVarMap map[string]bool, policy NumericPolicy, verbose bool) bool {
 	for _, statement := range block.Statements {
-		if !IsValidStatement(statement, declaredFunctionsMap, assignedVarMap, verbose) {
+		if !IsValidStatement(statement, declaredFunctionsMap, assignedVarMap, policy, verbose) {
 			return false
 		}
 	}
 	return true
 }
 
+// ValidateParameters checks the parameters of a function and adds them to the assignedVarMap as assigned variables:
+// - A parameter can't be declared twice.
+// - A default value must be a valid operand. It may only use the parameters preceding it.
+// - Parameters without a default value can't follow a parameter with a default value,
+// otherwise they could never be omitted by a positional call.
+func ValidateParameters(function Function, declaredFunctionsMap map[string]Function, assignedVarMap map[string]bool, policy NumericPolicy, verbose bool) bool {
+	hasDefault := false
+	for _, param := range function.Parameters {
+		if _, declared := assignedVarMap[param.Name]; declared {
+			if verbose {
+				fmt.Printf("Invalid parameter: %v of function: %v, parameter already declared\n", param.Name, function.Name)
+			}
+			return false
+		}
+		if param.Default == nil && hasDefault {
+			if verbose {
+				fmt.Printf("Invalid parameter: %v of function: %v, required parameter follows a parameter with a default value\n", param.Name, function.Name)
+			}
+			return false
+		}
+		if param.Default != nil {
+			hasDefault = true
+			if !IsValidOperand(*param.Default, false, declaredFunctionsMap, assignedVarMap, policy, verbose) {
+				if verbose {
+					fmt.Printf("Invalid default value for parameter: %v of function: %v\n", param.Name, function.Name)
+				}
+				return false
+			}
+		}
+		// parameters are always assigned, either by the caller or by their default value
+		assignedVarMap[param.Name] = true
+	}
+	return true
+}
+
+// ValidateProgramRec validates a program, accepting any numerical value strconv.ParseFloat accepts
 func ValidateProgramRec(program Program, verbose bool) bool {
-	// Create a map to store all function declarations
-	functionMap := make(map[string]int)
+	return ValidateProgram(program, PermissiveNumericPolicy, verbose)
+}
 
-	for _, function := range program.Functions {
-		functionMap[function.Name] = len(function.Parameters)
-	}
+// ValidateProgram validates a program, numerical values must be accepted by the given policy.
+// A program importing modules is validated by ValidateModule, along with the loaded modules.
+func ValidateProgram(program Program, policy NumericPolicy, verbose bool) bool {
+	return ValidateModule(program, nil, policy, verbose)
+}
 
+// validateFunctions validates the functions of a program, which can call the functions of functionMap
+func validateFunctions(program Program, functionMap map[string]Function, policy NumericPolicy, verbose bool) bool {
 	for _, function := range program.Functions {
 		// initialize a map to help with checking declared variables and assigned variables
 		// declared variables are set to false and assigned varaiables are set to true
 		// The map is initialized with the arguments passed to the function
 		assignedVarMap := make(map[string]bool)
-		for _, arg := range function.Parameters {
-			assignedVarMap[arg] = true
+		if !ValidateParameters(function, functionMap, assignedVarMap, policy, verbose) {
+			return false
 		}
-		if !ValidateBlock(function.Body, functionMap, assignedVarMap, verbose) {
+		if !ValidateBlock(function.Body, functionMap, assignedVarMap, policy, verbose) {
 			return false
 		}
 	}
-
 	return true
 }
 
 // -----------------------------------------
 // list declared but unused variables
 // -----------------------------------------
-func generateFunctionVarKey(functionName string, variableName string) string {
-	return functionName + "_" + variableName
+// VariableKey identifies a variable of a function
+type VariableKey struct {
+	Function string
+	Variable string
 }
 
-func UnusedVariables(program Program) []string {
-	// define a map to be populated with declared and used variables as follows:
-	// if variable is declared, add it to the map with value: false
-	// if it is used, set the value to true
-	usedVariables := make(map[string]bool)
+// VariableKind tells function parameters and local variables apart
+type VariableKind string
 
-	// Traverse each function in the program to get all used variables
-	for _, function := range program.Functions {
-		// add function arguments as declared varialbes
-		for _, arg := range function.Parameters {
-			// usedVariables[arg] = false
-			arg_key := generateFunctionVarKey(function.Name, arg)
-			usedVariables[arg_key] = false
-		}
-		PopulateUsedVariablesInBlock(function.Body, function.Name, usedVariables)
-	}
+const (
+	ParameterVariable VariableKind = "parameter"
+	LocalVariable     VariableKind = "local"
+)
 
-	unusedVariables := []string{}
+// UnusedVariable reports a declared variable which is never used
+type UnusedVariable struct {
+	Function string
+	Variable string
+	Kind     VariableKind
+	Scope    string // JSON path of the function declaring a parameter, or of the block declaring a local variable
+	Location string // JSON path of the parameter or of the variable_declaration statement
+	Assigned bool   // true if the variable is assigned, but the assigned values are never read
+}
 
-	// Check for unused variables
-	for variable := range usedVariables {
-		if !usedVariables[variable] {
-			unusedVariables = append(unusedVariables, variable)
-		}
+func (u UnusedVariable) String() string {
+	if u.Assigned {
+		return fmt.Sprintf("%v.%v (%v at %v, assigned but never read)", u.Function, u.Variable, u.Kind, u.Location)
 	}
+	return fmt.Sprintf("%v.%v (%v at %v)", u.Function, u.Variable, u.Kind, u.Location)
+}
 
-	return unusedVariables
+// UnusedVariablesOptions selects the unused variables which are not reported
+type UnusedVariablesOptions struct {
+	// IgnoreParameters skips unused parameters, which are often intentional, e.g. to keep the signature of a function
+	IgnoreParameters bool
+	// IgnoreNames skips the variables whose name matches, e.g. regexp.MustCompile("^_") for names starting with _
+	IgnoreNames *regexp.Regexp
 }
 
-// PopulateUsedVariablesInStatement populates a given map as following:
-// 	 if variable is declared, add it to the map with value: false
-// 	 if it is used, set the value to true. A variable is used if:
-// 		- used in an operation other than the left hand side of the assignment opertaion
-// 		- used in the argument to a function call
-func PopulateUsedVariablesInStatement(statement Statement, functionName string, usedVariables map[string]bool) {
-	switch statement.Type {
-	case "variable_declaration":
-		// usedVariables[statement.Variable] = false
-		arg_key := generateFunctionVarKey(functionName, statement.Variable)
-		usedVariables[arg_key] = false
-	case "operation":
-		for i, operand := range statement.Operands {
-			switch operand.Type {
-			case "variable":
-				// declare all variables in an operation to be used except the assigned variable
-				isAssignedVar := (i == 0 && statement.OperationType == "assignment")
-				if !isAssignedVar {
-					// usedVariables[operand.Variable] = true
-					arg_key := generateFunctionVarKey(functionName, operand.Variable)
-					usedVariables[arg_key] = true
-				}
-			case "function_call":
-				fallthrough
-			case "operation":
-				PopulateUsedVariablesInStatement(operand, functionName, usedVariables)
+// UnusedVariables lists the declared but unused parameters and local variables of the program
+func UnusedVariables(program Program) []UnusedVariable {
+	return UnusedVariablesWithOptions(program, UnusedVariablesOptions{})
+}
 
+// UnusedVariablesWithOptions lists the declared but unused variables which are not ignored by the options,
+// in program order. A variable is used if it is read:
+//   - in an operation other than the left hand side of the assignment opertaion
+//   - in the argument to a function call
+//   - in the default value of a later parameter
+//
+// Each declaration is tracked separately, so a variable declared in a block is told apart from a variable with
+// the same name declared in a sibling block.
+func UnusedVariablesWithOptions(program Program, options UnusedVariablesOptions) []UnusedVariable {
+	unusedVariables := []UnusedVariable{}
+	for i, function := range program.Functions {
+		accesses := collectAccesses(function, i)
+
+		used := make([]bool, len(accesses.declarations))
+		assigned := make([]bool, len(accesses.declarations))
+		for _, access := range accesses.accesses {
+			switch access.kind {
+			case accessUse:
+				used[access.declaration] = true
+			case accessAssignment:
+				assigned[access.declaration] = true
 			}
 		}
-	case "function_call":
-		for _, arg := range statement.Arguments {
-			switch arg.Type {
-			case "variable":
-				// usedVariables[arg.Variable] = true
-				arg_key := generateFunctionVarKey(functionName, arg.Variable)
-				usedVariables[arg_key] = true
-			case "function_call":
-				fallthrough
-			case "operation":
-				PopulateUsedVariablesInStatement(arg, functionName, usedVariables)
 
+		functionUnused := []UnusedVariable{}
+		for id, declaration := range accesses.declarations {
+			if used[id] {
+				continue
+			}
+			unused := UnusedVariable{
+				Function: function.Name,
+				Variable: declaration.Variable,
+				Kind:     LocalVariable,
+				Location: declaration.Path,
+				Assigned: assigned[id],
+			}
+			if declaration.Parameter {
+				unused.Kind = ParameterVariable
+				unused.Scope = functionPath(i)
+			} else {
+				unused.Scope = blockPath(declaration.Path)
 			}
+			if options.IgnoreParameters && unused.Kind == ParameterVariable {
+				continue
+			}
+			if options.IgnoreNames != nil && options.IgnoreNames.MatchString(unused.Variable) {
+				continue
+			}
+			functionUnused = append(functionUnused, unused)
 		}
-	case "block":
-		PopulateUsedVariablesInBlock(statement.Block, functionName, usedVariables)
+
+		// report the variables in program order: parameters come before local variables
+		sort.SliceStable(functionUnused, func(i, j int) bool {
+			a, b := functionUnused[i], functionUnused[j]
+			if a.Kind != b.Kind {
+				return a.Kind == ParameterVariable
+			}
+			return lessPath(a.Location, b.Location)
+		})
+		unusedVariables = append(unusedVariables, functionUnused...)
 	}
+	return unusedVariables
+}
 
+// PopulateUsedVariablesInStatement populates a given map as following:
+//
+//	if variable is declared, add it to the map with value: false
+//	if it is used, set the value to true. A variable is used if:
+//		- used in an operation other than the left hand side of the assignment opertaion
+//		- used in the argument to a function call
+//
+// The map is keyed by the function name and the variable name joined by an underscore. The variables of the function
+// already in the map are visible to the statement.
+//
+// Deprecated: the variables are keyed by name, so the variables with the same name declared in sibling blocks are
+// merged. Use UnusedVariablesWithOptions, which tracks each declaration.
+func PopulateUsedVariablesInStatement(statement Statement, functionName string, usedVariables map[string]bool) {
+	collector, seeded := newKeyedAccessCollector(functionName, usedVariables)
+	collector.statement(statement, "")
+	collector.populateUsedVariables(seeded, usedVariables)
 }
 
+// PopulateUsedVariablesInBlock populates the map with the variables of a block, see PopulateUsedVariablesInStatement.
+//
+// Deprecated: use UnusedVariablesWithOptions, which tracks each declaration.
 func PopulateUsedVariablesInBlock(block Block, functionName string, usedVariables map[string]bool) {
-	// Traverse each statement in the block
-	for _, statement := range block.Statements {
-		PopulateUsedVariablesInStatement(statement, functionName, usedVariables)
+	collector, seeded := newKeyedAccessCollector(functionName, usedVariables)
+	collector.block(block, "")
+	collector.populateUsedVariables(seeded, usedVariables)
+}
+
+func generateFunctionVarKey(functionName string, variableName string) string {
+	return functionName + "_" + variableName
+}
+
+// newKeyedAccessCollector returns a collector where the variables of the function already in the map are declared,
+// along with their number
+func newKeyedAccessCollector(functionName string, usedVariables map[string]bool) (*accessCollector, int) {
+	collector := &accessCollector{function: functionName, scopes: []map[string]int{make(map[string]int)}}
+	prefix := generateFunctionVarKey(functionName, "")
+	for key := range usedVariables {
+		if strings.HasPrefix(key, prefix) {
+			collector.declare(strings.TrimPrefix(key, prefix), "", false)
+		}
+	}
+	return collector, len(collector.result.declarations)
+}
+
+// populateUsedVariables adds the variables declared after the seeded ones to the map, and marks the used ones
+func (c *accessCollector) populateUsedVariables(seeded int, usedVariables map[string]bool) {
+	for _, declaration := range c.result.declarations[seeded:] {
+		usedVariables[generateFunctionVarKey(c.function, declaration.Variable)] = false
+	}
+	for _, access := range c.result.accesses {
+		if access.kind == accessUse {
+			usedVariables[generateFunctionVarKey(c.function, c.result.declarations[access.declaration].Variable)] = true
+		}
 	}
 }
 
@@ -390,6 +787,12 @@ func FindFunctionCalls(program Program) map[string]set {
 	// Iterate over each function in the program and populate functionCalls map
 	for _, function := range program.Functions {
 		GetFunctionCallsRecursively(function.Body.Statements, function.Name, functionCalls)
+		// default values of parameters are evaluated by the function itself when an argument is omitted
+		for _, param := range function.Parameters {
+			if param.Default != nil {
+				GetFunctionCallsRecursively([]Statement{*param.Default}, function.Name, functionCalls)
+			}
+		}
 	}
 	// unfold all dependancies
 	rolled_out_dependancies := RollOutDependencies(functionCalls)
@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"reflect"
+	"regexp"
 	"testing"
 )
 
@@ -103,6 +104,24 @@ func TestValidateProgramRec_nestedFunctionCallUndeclaredVariable_fixed(t *testin
 	validateProgramTestCase(t, filepath, expectedResult)
 }
 
+func TestValidateProgramRec_FunctionCallWithDefaultParameters(t *testing.T) {
+	expectedResult := true
+	filepath := "../data/valid/function_call_with_default_parameters.json"
+	validateProgramTestCase(t, filepath, expectedResult)
+}
+
+func TestValidateProgramRec_FunctionCallWithNamedArguments(t *testing.T) {
+	expectedResult := true
+	filepath := "../data/valid/function_call_with_named_arguments.json"
+	validateProgramTestCase(t, filepath, expectedResult)
+}
+
+func TestValidateProgramRec_StringAndBooleanLiterals(t *testing.T) {
+	expectedResult := true
+	filepath := "../data/valid/string_and_boolean_literals.json"
+	validateProgramTestCase(t, filepath, expectedResult)
+}
+
 // --------------------------
 // Test invalid programs
 // --------------------------
@@ -147,6 +166,107 @@ func TestValidateProgramRec_functionCallWithInvalidArity(t *testing.T) {
 	validateProgramTestCase(t, filepath, expectedResult)
 }
 
+func TestValidateProgramRec_functionCallMissingRequiredParameter(t *testing.T) {
+	expectedResult := false
+	filepath := "../data/invalid/function_call_missing_required_parameter.json"
+	validateProgramTestCase(t, filepath, expectedResult)
+}
+
+func TestValidateProgramRec_requiredParameterAfterDefault(t *testing.T) {
+	expectedResult := false
+	filepath := "../data/invalid/required_parameter_after_default.json"
+	validateProgramTestCase(t, filepath, expectedResult)
+}
+
+func TestValidateProgramRec_namedArgumentUnknownParameter(t *testing.T) {
+	expectedResult := false
+	filepath := "../data/invalid/named_argument_unknown_parameter.json"
+	validateProgramTestCase(t, filepath, expectedResult)
+}
+
+func TestValidateProgramRec_namedArgumentBoundTwice(t *testing.T) {
+	expectedResult := false
+	filepath := "../data/invalid/named_argument_bound_twice.json"
+	validateProgramTestCase(t, filepath, expectedResult)
+}
+
+func TestValidateProgramRec_positionalArgumentAfterNamed(t *testing.T) {
+	expectedResult := false
+	filepath := "../data/invalid/positional_argument_after_named.json"
+	validateProgramTestCase(t, filepath, expectedResult)
+}
+
+func TestValidateProgramRec_invalidStringEscape(t *testing.T) {
+	expectedResult := false
+	filepath := "../data/invalid/invalid_string_escape.json"
+	validateProgramTestCase(t, filepath, expectedResult)
+}
+
+func TestValidateProgramRec_invalidBooleanLiteral(t *testing.T) {
+	expectedResult := false
+	filepath := "../data/invalid/invalid_boolean_literal.json"
+	validateProgramTestCase(t, filepath, expectedResult)
+}
+
+func TestValidateProgramRec_BuiltinFunctionCall(t *testing.T) {
+	expectedResult := true
+	filepath := "../data/run/print_sum.json"
+	validateProgramTestCase(t, filepath, expectedResult)
+}
+
+func TestValidateProgramRec_BuiltinFunctionNamedArgument(t *testing.T) {
+	program := Program{Functions: []Function{{Name: "main", Body: Block{Statements: []Statement{
+		{Type: "function_call", CalledFunction: "print", Arguments: []Statement{{Type: "numerical", Value: "1", ParameterName: "value"}}},
+	}}}}}
+	if ValidateProgramRec(program, false) {
+		t.Errorf("A named argument passed to a built-in function should be invalid")
+	}
+}
+
+func TestUnescapeString(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected string
+		valid    bool
+	}{
+		{`done`, "done", true},
+		{`say \"hi\"\n`, "say \"hi\"\n", true},
+		{`back\\slash\ttab\r`, "back\\slash\ttab\r", true},
+		{`caf\u00e9`, "café", true},
+		{`done\q`, "", false},
+		{`done\`, "", false},
+		{`\u00e`, "", false},
+		{`\u00zz`, "", false},
+	}
+	for _, testCase := range testCases {
+		result, err := UnescapeString(testCase.value)
+		if (err == nil) != testCase.valid || result != testCase.expected {
+			t.Errorf("UnescapeString(%q) = %q, %v, want %q, valid: %v", testCase.value, result, err, testCase.expected, testCase.valid)
+		}
+	}
+}
+
+func TestBindArguments(t *testing.T) {
+	defaultValue := Statement{Type: "numerical", Value: "3"}
+	function := Function{
+		Name:       "calculateSum",
+		Parameters: []Parameter{{Name: "a"}, {Name: "b"}, {Name: "c", Default: &defaultValue}},
+	}
+	arguments := []Statement{
+		{Type: "numerical", Value: "1"},
+		{Type: "variable", Variable: "x", ParameterName: "b"},
+	}
+
+	result, err := BindArguments(function, arguments)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectedResult := []Statement{arguments[0], arguments[1], defaultValue}
+	if !reflect.DeepEqual(result, expectedResult) {
+		t.Errorf("Unexpected result. Got %v, want %v", result, expectedResult)
+	}
+}
+
 // -------------------------------------
 // Test functions dependancies
 // -------------------------------------
@@ -242,44 +362,22 @@ func helperFprTestCase(t *testing.T, filepath string, expectedResult []string) {
 	program := ReadTestCaseFromJSON(filepath)
 
 	// Call the function
-	result := UnusedVariables(program)
-
-	// Compare the result with the expected output
-	// if !reflect.DeepEqual(result, expectedResult) {
-	if !sameStringSlice(result, expectedResult) {
-		t.Errorf("Unexpected result. Got %v, want %v", result, expectedResult)
+	result := []string{}
+	for _, unused := range UnusedVariables(program) {
+		result = append(result, unused.String())
 	}
-}
 
-// sameStringSlice function to compare 2 lists contains the same elements regardless of the order
-func sameStringSlice(x []string, y []string) bool {
-	if len(x) != len(y) {
-		return false
-	}
-	// create a map of string -> int
-	diff := make(map[string]int, len(x))
-	for _, _x := range x {
-		// 0 value for int is 0, so just increment a counter for the string
-		diff[_x]++
-	}
-	for _, _y := range y {
-		// If the string _y is not in diff bail out early
-		if _, ok := diff[_y]; !ok {
-			return false
-		}
-		diff[_y] -= 1
-		if diff[_y] == 0 {
-			delete(diff, _y)
-		}
+	// Compare the result with the expected output, the variables are listed in program order
+	if !reflect.DeepEqual(result, expectedResult) {
+		t.Errorf("Unexpected result. Got %v, want %v", result, expectedResult)
 	}
-	return len(diff) == 0
 }
 
 // --------------------------
 // Test unused variables
 // --------------------------
 func TestUnusedVariables_TestCase1(t *testing.T) {
-	expectedResult := []string{"myFunction_result"}
+	expectedResult := []string{"myFunction.result (local at functions[0].body.statements[0].block.statements[0])"}
 	filepath := "../data/unused_variables/one_unused_variable.json"
 	helperFprTestCase(t, filepath, expectedResult)
 }
@@ -291,7 +389,10 @@ func TestUnusedVariables_VariableUsedInFunctionCall(t *testing.T) {
 }
 
 func TestUnusedVariables_VariablesFromFunctionParameters(t *testing.T) {
-	expectedResult := []string{"myFunction_param_1", "myFunction_result"}
+	expectedResult := []string{
+		"myFunction.param_1 (parameter at functions[0].parameters[0])",
+		"myFunction.result (local at functions[0].body.statements[0].block.statements[0])",
+	}
 	filepath := "../data/unused_variables/unused_variables_from_function_parameters.json"
 	helperFprTestCase(t, filepath, expectedResult)
 }
@@ -303,13 +404,100 @@ func TestUnusedVariables_NestedExampleWithFunctionCallAndPperations(t *testing.T
 }
 
 func TestUnusedVariables_UnusedVariablesInNestedBlocks(t *testing.T) {
-	expectedResult := []string{"main_y", "main_z"}
+	expectedResult := []string{
+		"main.y (local at functions[0].body.statements[0].block.statements[1].block.statements[0])",
+		"main.z (local at functions[0].body.statements[0].block.statements[1].block.statements[1].block.statements[0])",
+	}
 	filepath := "../data/unused_variables/unused_variables_in_nested_blocks.json"
 	helperFprTestCase(t, filepath, expectedResult)
 }
 
 func TestUnusedVariables_UnusedVariableDeclaredInTwoFunctions(t *testing.T) {
-	expectedResult := []string{"main_result"}
+	expectedResult := []string{"main.result (local at functions[0].body.statements[0])"}
 	filepath := "../data/unused_variables/same_var_declared_in_two_places.json"
 	helperFprTestCase(t, filepath, expectedResult)
 }
+
+func TestUnusedVariables_ParameterUsedInDefaultValue(t *testing.T) {
+	expectedResult := []string{}
+	filepath := "../data/valid/function_call_with_default_parameters.json"
+	helperFprTestCase(t, filepath, expectedResult)
+}
+
+func TestUnusedVariables_VariableUsedInNamedArgument(t *testing.T) {
+	expectedResult := []string{}
+	filepath := "../data/valid/function_call_with_named_arguments.json"
+	helperFprTestCase(t, filepath, expectedResult)
+}
+
+func TestUnusedVariables_VariablesAssignedLiterals(t *testing.T) {
+	expectedResult := []string{"main.done (local at functions[0].body.statements[0], assigned but never read)", "display.message (parameter at functions[1].parameters[0])"}
+	filepath := "../data/valid/string_and_boolean_literals.json"
+	helperFprTestCase(t, filepath, expectedResult)
+}
+
+func TestUnusedVariables_CollidingFunctionAndVariableNames(t *testing.T) {
+	expectedResult := []string{"a_b.c (local at functions[0].body.statements[0])"}
+	filepath := "../data/unused_variables/colliding_function_and_variable_names.json"
+	helperFprTestCase(t, filepath, expectedResult)
+}
+
+func TestUnusedVariables_UnusedInSiblingBlocks(t *testing.T) {
+	// y is used in the block following the one declaring x
+	expectedResult := []string{"main.x (local at functions[0].body.statements[0].block.statements[0])"}
+	filepath := "../data/unused_variables/unused_in_sibling_blocks.json"
+	helperFprTestCase(t, filepath, expectedResult)
+}
+
+func TestPopulateUsedVariablesInBlock(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/unused_variables/unused_in_sibling_blocks.json")
+	usedVariables := make(map[string]bool)
+	PopulateUsedVariablesInBlock(program.Functions[0].Body, "main", usedVariables)
+
+	expectedUsed := map[string]bool{"main_x": false, "main_y": true}
+	if !reflect.DeepEqual(usedVariables, expectedUsed) {
+		t.Errorf("Unexpected used variables. Got %v, want %v", usedVariables, expectedUsed)
+	}
+
+	// the variables already in the map are visible to the statement
+	statement := Statement{Type: "function_call", CalledFunction: "display", Arguments: []Statement{{Type: "variable", Variable: "x"}}}
+	PopulateUsedVariablesInStatement(statement, "main", usedVariables)
+	if !usedVariables["main_x"] {
+		t.Errorf("x should be used")
+	}
+}
+
+func TestUnusedVariables_VariableOnlyAssigned(t *testing.T) {
+	// a is read by the assignment of total, then reassigned, while total is only assigned
+	expectedResult := []string{"main.total (local at functions[0].body.statements[0], assigned but never read)"}
+	filepath := "../data/unused_variables/variable_only_assigned.json"
+	helperFprTestCase(t, filepath, expectedResult)
+}
+
+func TestUnusedVariablesWithOptions_IgnoreParameters(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/unused_variables/unused_variables_from_function_parameters.json")
+	expectedResult := []UnusedVariable{
+		{
+			Function: "myFunction",
+			Variable: "result",
+			Kind:     LocalVariable,
+			Scope:    "functions[0].body.statements[0].block",
+			Location: "functions[0].body.statements[0].block.statements[0]",
+		},
+	}
+	result := UnusedVariablesWithOptions(program, UnusedVariablesOptions{IgnoreParameters: true})
+	if !reflect.DeepEqual(result, expectedResult) {
+		t.Errorf("Unexpected result. Got %v, want %v", result, expectedResult)
+	}
+}
+
+func TestUnusedVariablesWithOptions_IgnoreNames(t *testing.T) {
+	program := ReadTestCaseFromJSON("../data/unused_variables/unused_variables_from_function_parameters.json")
+	expectedResult := []UnusedVariable{
+		{Function: "myFunction", Variable: "param_1", Kind: ParameterVariable, Scope: "functions[0]", Location: "functions[0].parameters[0]"},
+	}
+	result := UnusedVariablesWithOptions(program, UnusedVariablesOptions{IgnoreNames: regexp.MustCompile("^res")})
+	if !reflect.DeepEqual(result, expectedResult) {
+		t.Errorf("Unexpected result. Got %v, want %v", result, expectedResult)
+	}
+}
@@ -0,0 +1,474 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// -----------------------------------------
+// YAML decoder
+// -----------------------------------------
+/*
+	decodeYAML reads the subset of YAML needed to write programs by hand:
+		- block mappings, e.g. "name: main", and block sequences, e.g. "- a", nested using the indentation.
+		- flow sequences and flow mappings, e.g. [a, b] and {type: variable, variable: x}, which may span several lines.
+		- plain scalars, single quoted scalars and double quoted scalars with their escape sequences.
+		- null values, written ~, null or left empty.
+		- comments starting with #, and a document start marker --- on the first line.
+	Block scalars (| and >), anchors, aliases, tags and multiple documents are rejected with an error.
+*/
+
+// yamlLine is a line of a YAML document, without its indentation and comment
+type yamlLine struct {
+	number  int // counting from 1
+	indent  int // number of spaces before the content
+	content string
+}
+
+// yamlParser holds the state of decodeYAML
+type yamlParser struct {
+	lines    []yamlLine
+	position int // index of the current line
+	column   int // index of the current byte in the content of the current line, for flow values
+}
+
+func decodeYAML(data []byte) (Program, SourceMap, error) {
+	lines, err := splitYAMLLines(string(data))
+	if err != nil {
+		return Program{}, nil, err
+	}
+	p := yamlParser{lines: lines}
+	root := &sourceNode{kind: nodeNull, position: Position{Line: 1, Column: 1}}
+	if len(lines) > 0 {
+		root, err = p.block(lines[0].indent)
+		if err != nil {
+			return Program{}, nil, err
+		}
+	}
+	if p.position < len(p.lines) {
+		line := p.lines[p.position]
+		return Program{}, nil, &SyntaxError{Line: line.number, Column: line.indent + 1, Message: "unexpected indentation"}
+	}
+	return decodeDocument(root)
+}
+
+// splitYAMLLines returns the lines holding some content, without their comment and trailing spaces
+func splitYAMLLines(source string) ([]yamlLine, error) {
+	lines := []yamlLine{}
+	for i, text := range strings.Split(source, "\n") {
+		text = strings.TrimRight(text, "\r")
+		content := strings.TrimLeft(text, " ")
+		indent := len(text) - len(content)
+		if strings.HasPrefix(content, "\t") {
+			return nil, &SyntaxError{Line: i + 1, Column: indent + 1, Message: "tabs can't be used for indentation"}
+		}
+		content = strings.TrimRight(stripYAMLComment(content), " \t")
+		if content == "" {
+			continue
+		}
+		if content == "---" || strings.HasPrefix(content, "--- ") || content == "..." {
+			if len(lines) > 0 {
+				return nil, &SyntaxError{Line: i + 1, Column: indent + 1, Message: "multiple documents are not supported"}
+			}
+			content = strings.TrimLeft(strings.TrimPrefix(content, "---"), " ")
+			if content == "" || content == "..." {
+				continue
+			}
+			indent = len(text) - len(content)
+		}
+		lines = append(lines, yamlLine{number: i + 1, indent: indent, content: content})
+	}
+	return lines, nil
+}
+
+// stripYAMLComment removes the comment of a line, starting with a # at the start of the line or after a space,
+// outside of the quoted scalars
+func stripYAMLComment(content string) string {
+	quote := byte(0)
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case quote == '"' && c == '\\', quote == '\'' && c == '\'' && i+1 < len(content) && content[i+1] == '\'':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '#' && (i == 0 || content[i-1] == ' ' || content[i-1] == '\t'):
+			return content[:i]
+		case (c == '"' || c == '\'') && startsYAMLToken(content[:i]):
+			quote = c
+		}
+	}
+	return content
+}
+
+// startsYAMLToken returns true if a quote following the text starts a quoted scalar, rather than being part of a
+// plain scalar, e.g. it's
+func startsYAMLToken(before string) bool {
+	before = strings.TrimRight(before, " \t")
+	return before == "" || strings.ContainsAny(before[len(before)-1:], "[{,:-?")
+}
+
+func (p *yamlParser) errorf(line yamlLine, column int, format string, args ...interface{}) error {
+	return &SyntaxError{Line: line.number, Column: line.indent + column + 1, Message: fmt.Sprintf(format, args...)}
+}
+
+// yamlPosition returns the position of a byte of the content of a line
+func yamlPosition(line yamlLine, column int) Position {
+	return Position{Line: line.number, Column: line.indent + column + 1}
+}
+
+// block parses the block node starting at the current line, whose content is at the given indentation
+func (p *yamlParser) block(indent int) (*sourceNode, error) {
+	line := p.lines[p.position]
+	if isYAMLSequenceEntry(line.content) {
+		return p.sequence(indent)
+	}
+	if _, _, ok, err := p.mappingKey(); err != nil || ok {
+		if err != nil {
+			return nil, err
+		}
+		return p.mapping(indent)
+	}
+	return p.inlineValue(0)
+}
+
+func isYAMLSequenceEntry(content string) bool {
+	return content == "-" || strings.HasPrefix(content, "- ")
+}
+
+// sequence parses the entries of a block sequence at the given indentation
+func (p *yamlParser) sequence(indent int) (*sourceNode, error) {
+	node := &sourceNode{kind: nodeSequence, values: []*sourceNode{}, position: yamlPosition(p.lines[p.position], 0)}
+	for p.position < len(p.lines) && p.lines[p.position].indent == indent && isYAMLSequenceEntry(p.lines[p.position].content) {
+		line := p.lines[p.position]
+		rest := strings.TrimLeft(line.content[1:], " ")
+		var element *sourceNode
+		var err error
+		if rest == "" {
+			p.position++
+			element, err = p.nested(indent, yamlPosition(line, 0), false)
+		} else {
+			// the content following the dash is parsed as a line of its own, e.g. the first key of a mapping
+			entryIndent := indent + len(line.content) - len(rest)
+			p.lines[p.position] = yamlLine{number: line.number, indent: entryIndent, content: rest}
+			element, err = p.block(entryIndent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		node.values = append(node.values, element)
+	}
+	return node, nil
+}
+
+// mapping parses the entries of a block mapping at the given indentation
+func (p *yamlParser) mapping(indent int) (*sourceNode, error) {
+	node := &sourceNode{kind: nodeMapping, position: yamlPosition(p.lines[p.position], 0)}
+	for p.position < len(p.lines) && p.lines[p.position].indent == indent {
+		line := p.lines[p.position]
+		key, valueColumn, ok, err := p.mappingKey()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, p.errorf(line, 0, "expected a mapping entry \"key: value\"")
+		}
+		if node.field(key) != nil {
+			return nil, p.errorf(line, 0, "duplicate key: %v", key)
+		}
+		var value *sourceNode
+		if valueColumn == len(line.content) {
+			p.position++
+			value, err = p.nested(indent, yamlPosition(line, 0), true)
+		} else {
+			value, err = p.inlineValue(valueColumn)
+		}
+		if err != nil {
+			return nil, err
+		}
+		node.set(key, value)
+	}
+	return node, nil
+}
+
+// nested parses the value of a key or of a sequence entry written on the following lines, which is null if there
+// is none. The value of a key may be a sequence at the same indentation as the key.
+func (p *yamlParser) nested(indent int, position Position, isKey bool) (*sourceNode, error) {
+	if p.position < len(p.lines) {
+		next := p.lines[p.position]
+		if next.indent > indent {
+			return p.block(next.indent)
+		}
+		if next.indent == indent && isKey && isYAMLSequenceEntry(next.content) {
+			return p.sequence(indent)
+		}
+	}
+	return &sourceNode{kind: nodeNull, position: position}, nil
+}
+
+// mappingKey returns the key of the current line if it holds a mapping entry, along with the column of its value,
+// which is the length of the content if the value is on the following lines
+func (p *yamlParser) mappingKey() (key string, valueColumn int, ok bool, err error) {
+	content := p.lines[p.position].content
+	end := 0
+	switch content[0] {
+	case '"', '\'':
+		p.column = 0
+		scalar, err := p.quoted()
+		if err != nil {
+			return "", 0, false, err
+		}
+		key, end = scalar.value, p.column
+		for end < len(content) && content[end] == ' ' {
+			end++
+		}
+		if end == len(content) || content[end] != ':' {
+			return "", 0, false, nil
+		}
+	case '[', '{':
+		return "", 0, false, nil
+	default:
+		end = strings.Index(content+" ", ": ")
+		if end < 0 {
+			return "", 0, false, nil
+		}
+		key = strings.TrimRight(content[:end], " ")
+	}
+	// the colon must be followed by a space or end the line
+	if end+1 < len(content) && content[end+1] != ' ' {
+		return "", 0, false, nil
+	}
+	valueColumn = end + 1
+	for valueColumn < len(content) && content[valueColumn] == ' ' {
+		valueColumn++
+	}
+	return key, valueColumn, true, nil
+}
+
+// inlineValue parses the value starting at the given column of the current line: a flow collection, a quoted
+// scalar or a plain scalar, which must end the line
+func (p *yamlParser) inlineValue(column int) (*sourceNode, error) {
+	line := p.lines[p.position]
+	p.column = column
+	var node *sourceNode
+	var err error
+	switch c := line.content[column]; c {
+	case '[', '{', '"', '\'':
+		node, err = p.flowValue()
+		if err != nil {
+			return nil, err
+		}
+		// the value may span several lines, the current line is the one holding its end
+		line = p.lines[p.position]
+		if p.column < len(line.content) {
+			return nil, p.errorf(line, p.column, "unexpected content after the value: %v", line.content[p.column:])
+		}
+	case '|', '>':
+		return nil, p.errorf(line, column, "block scalars are not supported")
+	case '&', '*', '!':
+		return nil, p.errorf(line, column, "anchors, aliases and tags are not supported")
+	default:
+		value := line.content[column:]
+		if strings.Contains(value+" ", ": ") {
+			return nil, p.errorf(line, column, "unexpected mapping entry in a value: %v", value)
+		}
+		node = plainYAMLScalar(value, yamlPosition(line, column))
+	}
+	p.position++
+	return node, nil
+}
+
+// plainYAMLScalar returns the node of a plain scalar, which is null if written ~ or null
+func plainYAMLScalar(value string, position Position) *sourceNode {
+	switch value {
+	case "~", "null", "Null", "NULL":
+		return &sourceNode{kind: nodeNull, position: position}
+	}
+	return &sourceNode{kind: nodeScalar, value: value, position: position}
+}
+
+// current returns the current byte of a flow value, '\n' at the end of a line and 0 at the end of the document
+func (p *yamlParser) current() byte {
+	if p.position >= len(p.lines) {
+		return 0
+	}
+	if p.column >= len(p.lines[p.position].content) {
+		return '\n'
+	}
+	return p.lines[p.position].content[p.column]
+}
+
+// skipFlowSpaces skips the spaces and the ends of lines inside a flow collection
+func (p *yamlParser) skipFlowSpaces() {
+	for {
+		switch p.current() {
+		case ' ', '\t':
+			p.column++
+		case '\n':
+			p.position++
+			p.column = 0
+		default:
+			return
+		}
+	}
+}
+
+// flowValue parses a flow collection or a scalar, which ends on the current line
+func (p *yamlParser) flowValue() (*sourceNode, error) {
+	p.skipFlowSpaces()
+	if p.position >= len(p.lines) {
+		last := p.lines[len(p.lines)-1]
+		return nil, p.errorf(last, len(last.content), "expected a value, found the end of the document")
+	}
+	line := p.lines[p.position]
+	start := yamlPosition(line, p.column)
+	switch p.current() {
+	case '[':
+		return p.flowCollection(nodeSequence, ']', start)
+	case '{':
+		return p.flowCollection(nodeMapping, '}', start)
+	case '"', '\'':
+		return p.quoted()
+	case '&', '*', '!', '|', '>':
+		return nil, p.errorf(line, p.column, "anchors, aliases, tags and block scalars are not supported")
+	}
+	end := p.column
+	for end < len(line.content) && !strings.ContainsRune(",[]{}", rune(line.content[end])) &&
+		!(line.content[end] == ':' && (end+1 == len(line.content) || strings.ContainsRune(" ,]}", rune(line.content[end+1])))) {
+		end++
+	}
+	value := strings.TrimRight(line.content[p.column:end], " \t")
+	if value == "" {
+		return nil, p.errorf(line, p.column, "expected a value, found '%c'", line.content[p.column])
+	}
+	p.column = end
+	return plainYAMLScalar(value, start), nil
+}
+
+// flowCollection parses the entries of a flow sequence or a flow mapping, from the opening bracket to the closing one
+func (p *yamlParser) flowCollection(kind nodeKind, closing byte, start Position) (*sourceNode, error) {
+	node := &sourceNode{kind: kind, position: start}
+	if kind == nodeSequence {
+		node.values = []*sourceNode{}
+	}
+	p.column++
+	for {
+		p.skipFlowSpaces()
+		if p.current() == closing {
+			p.column++
+			return node, nil
+		}
+		if p.current() == 0 {
+			return nil, &SyntaxError{Line: start.Line, Column: start.Column, Message: fmt.Sprintf("unterminated flow collection, expected '%c'", closing)}
+		}
+		if kind == nodeSequence {
+			element, err := p.flowValue()
+			if err != nil {
+				return nil, err
+			}
+			node.values = append(node.values, element)
+		} else {
+			keyLine, keyColumn := p.lines[p.position], p.column
+			key, err := p.flowValue()
+			if err != nil {
+				return nil, err
+			}
+			if key.kind != nodeScalar {
+				return nil, p.errorf(keyLine, keyColumn, "expected a key")
+			}
+			if node.field(key.value) != nil {
+				return nil, p.errorf(keyLine, keyColumn, "duplicate key: %v", key.value)
+			}
+			p.skipFlowSpaces()
+			if p.current() != ':' {
+				return nil, p.flowError("expected ':' after the key %v", key.value)
+			}
+			p.column++
+			p.skipFlowSpaces()
+			value := &sourceNode{kind: nodeNull, position: key.position}
+			if p.current() != ',' && p.current() != closing {
+				if value, err = p.flowValue(); err != nil {
+					return nil, err
+				}
+			}
+			node.set(key.value, value)
+		}
+		p.skipFlowSpaces()
+		switch p.current() {
+		case ',':
+			p.column++
+		case closing:
+		default:
+			return nil, p.flowError("expected ',' or '%c' in the flow collection", closing)
+		}
+	}
+}
+
+// flowError returns an error at the current byte of a flow value
+func (p *yamlParser) flowError(format string, args ...interface{}) error {
+	if p.position >= len(p.lines) {
+		last := p.lines[len(p.lines)-1]
+		return p.errorf(last, len(last.content), format+", found the end of the document", args...)
+	}
+	return p.errorf(p.lines[p.position], p.column, format, args...)
+}
+
+// quoted parses a single or double quoted scalar, which must end on the current line
+func (p *yamlParser) quoted() (*sourceNode, error) {
+	line := p.lines[p.position]
+	start := p.column
+	quote := line.content[start]
+	var value strings.Builder
+	for i := start + 1; i < len(line.content); i++ {
+		c := line.content[i]
+		switch {
+		case c == quote && quote == '\'' && i+1 < len(line.content) && line.content[i+1] == '\'':
+			value.WriteByte('\'')
+			i++
+		case c == quote:
+			p.column = i + 1
+			return &sourceNode{kind: nodeScalar, value: value.String(), position: yamlPosition(line, start)}, nil
+		case c == '\\' && quote == '"':
+			length, err := unescapeYAML(line.content[i:], &value)
+			if err != nil {
+				return nil, p.errorf(line, i, "%v", err)
+			}
+			i += length - 1
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return nil, p.errorf(line, start, "unterminated quoted scalar")
+}
+
+// yamlEscapes are the escape sequences of double quoted scalars, other than \x, \u and \U
+var yamlEscapes = map[byte]string{'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n", 'v': "\v", 'f': "\f",
+	'r': "\r", 'e': "\x1b", ' ': " ", '"': "\"", '/': "/", '\\': "\\", 'N': "\u0085", '_': "\u00a0", 'L': "\u2028", 'P': "\u2029"}
+
+// unescapeYAML writes the character of the escape sequence at the start of the text, returning its length
+func unescapeYAML(text string, value *strings.Builder) (int, error) {
+	if len(text) < 2 {
+		return 0, fmt.Errorf("unterminated escape sequence")
+	}
+	if replacement, ok := yamlEscapes[text[1]]; ok {
+		value.WriteString(replacement)
+		return 2, nil
+	}
+	digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[text[1]]
+	if digits == 0 {
+		return 0, fmt.Errorf("invalid escape sequence: \\%c", text[1])
+	}
+	if len(text) < 2+digits {
+		return 0, fmt.Errorf("invalid escape sequence: %v", text)
+	}
+	code, err := strconv.ParseUint(text[2:2+digits], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return 0, fmt.Errorf("invalid escape sequence: %v", text[:2+digits])
+	}
+	value.WriteRune(rune(code))
+	return 2 + digits, nil
+}